@@ -2,6 +2,7 @@ package base
 
 import (
 	"fmt"
+	"github.com/ElaraLang/elara/interpreter"
 	"github.com/mholt/archiver"
 	"io"
 	"io/ioutil"
@@ -13,12 +14,12 @@ import (
 	"time"
 )
 
-func ExecuteFull(fileName string, scriptMode bool) {
+func ExecuteFull(fileName string, scriptMode bool, options ...interpreter.Option) {
 	LoadStdLib()
 
 	input := loadFile(fileName)
 	start := time.Now()
-	_, lexTime, parseTime, execTime := Execute(&fileName, string(input), scriptMode)
+	_, lexTime, parseTime, execTime := ExecuteChecked(&fileName, string(input), scriptMode, options...)
 
 	totalTime := time.Since(start)
 
@@ -27,6 +28,12 @@ func ExecuteFull(fileName string, scriptMode bool) {
 	fmt.Println("===========================")
 }
 
+//DisassembleFull prints the bytecode that a file compiles to
+func DisassembleFull(fileName string) {
+	input := loadFile(fileName)
+	fmt.Print(Disassemble(&fileName, string(input)))
+}
+
 func LoadStdLib() {
 	usr, err := user.Current()
 	if err != nil {
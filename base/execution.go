@@ -5,36 +5,84 @@ import (
 	"github.com/ElaraLang/elara/interpreter"
 	"github.com/ElaraLang/elara/lexer"
 	"github.com/ElaraLang/elara/parserlegacy"
+	"github.com/ElaraLang/elara/typer"
 	"os"
 	"time"
 )
 
-func Execute(fileName *string, code string, scriptMode bool) (results []*interpreter.Value, lexTime, parseTime, execTime time.Duration) {
+//Execute runs code without checking its types first, so type errors are only found as the code runs
+func Execute(fileName *string, code string, scriptMode bool, options ...interpreter.Option) (results []*interpreter.Value, lexTime, parseTime, execTime time.Duration) {
+	return execute(fileName, code, scriptMode, false, options)
+}
+
+//ExecuteChecked type checks code before running it, and does not run it at all if any type errors are found
+func ExecuteChecked(fileName *string, code string, scriptMode bool, options ...interpreter.Option) (results []*interpreter.Value, lexTime, parseTime, execTime time.Duration) {
+	return execute(fileName, code, scriptMode, true, options)
+}
+
+func execute(fileName *string, code string, scriptMode bool, typeCheck bool, options []interpreter.Option) (results []*interpreter.Value, lexTime, parseTime, execTime time.Duration) {
 	start := time.Now()
 	result := lexer.Lex(code)
 	lexTime = time.Since(start)
 
 	start = time.Now()
-	psr := parserlegacy.NewParser(result)
-	parseRes, errs := psr.Parse()
+	parseRes, ok := parse(fileName, result)
 	parseTime = time.Since(start)
+	if !ok {
+		return []*interpreter.Value{}, lexTime, parseTime, time.Duration(-1)
+	}
 
-	if len(errs) != 0 {
-		file := "Unknown File"
-		if fileName != nil {
-			file = *fileName
+	file := fileDescription(fileName)
+
+	if typeCheck {
+		checker := typer.NewTyper(parseRes)
+		typeErrors := checker.HandleTyping()
+		for _, warning := range checker.Warnings {
+			_, _ = os.Stderr.WriteString(fmt.Sprintf("%s\n", warning))
 		}
-		_, _ = os.Stderr.WriteString(fmt.Sprintf("Syntax Errors found in %s: \n", file))
-		for _, err := range errs {
-			_, _ = os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
+		if len(typeErrors) != 0 {
+			_, _ = os.Stderr.WriteString(fmt.Sprintf("Type Errors found in %s: \n", file))
+			for _, err := range typeErrors {
+				_, _ = os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
+			}
+			return []*interpreter.Value{}, lexTime, parseTime, time.Duration(-1)
 		}
-		return []*interpreter.Value{}, lexTime, parseTime, time.Duration(-1)
 	}
 
 	start = time.Now()
-	evaluator := interpreter.NewInterpreter(parseRes)
+	evaluator := interpreter.NewInterpreter(parseRes, options...)
 
 	results = evaluator.Exec(scriptMode)
 	execTime = time.Since(start)
 	return results, lexTime, parseTime, execTime
 }
+
+//Disassemble compiles code to bytecode without running it, and returns the listing of its instructions
+func Disassemble(fileName *string, code string) string {
+	parseRes, ok := parse(fileName, lexer.Lex(code))
+	if !ok {
+		return ""
+	}
+	return interpreter.NewInterpreter(parseRes).Disassemble()
+}
+
+//parse parses the tokens of a file, reporting any syntax errors
+func parse(fileName *string, tokens []lexer.Token) ([]parserlegacy.Stmt, bool) {
+	psr := parserlegacy.NewParser(tokens)
+	parseRes, errs := psr.Parse()
+	if len(errs) != 0 {
+		_, _ = os.Stderr.WriteString(fmt.Sprintf("Syntax Errors found in %s: \n", fileDescription(fileName)))
+		for _, err := range errs {
+			_, _ = os.Stderr.WriteString(fmt.Sprintf("%s\n", err))
+		}
+		return nil, false
+	}
+	return parseRes, true
+}
+
+func fileDescription(fileName *string) string {
+	if fileName == nil {
+		return "Unknown File"
+	}
+	return *fileName
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
 	"github.com/urfave/cli/v2"
 	"os"
 )
@@ -18,6 +19,26 @@ func main() {
 				Value: false,
 				Usage: "Script Mode (print the result of every expression)",
 			},
+			&cli.BoolFlag{
+				Name:  "bytecode",
+				Value: false,
+				Usage: "Compile the code to bytecode and run it on the VM",
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "disasm",
+				Usage:     "Print the bytecode that a file compiles to",
+				ArgsUsage: "file.elr",
+				Action: func(c *cli.Context) error {
+					fileName := c.Args().Get(0)
+					if fileName == "" {
+						return errors.New("no file provided to disassemble - nothing to do")
+					}
+					base.DisassembleFull(fileName)
+					return nil
+				},
+			},
 		},
 		Action: func(c *cli.Context) error {
 			fileName := c.Args().Get(0)
@@ -26,7 +47,11 @@ func main() {
 			}
 
 			scriptMode := c.Bool("script")
-			base.ExecuteFull(fileName, scriptMode)
+			var options []interpreter.Option
+			if c.Bool("bytecode") {
+				options = append(options, interpreter.WithBytecode())
+			}
+			base.ExecuteFull(fileName, scriptMode, options...)
 			return nil
 		},
 	}
@@ -0,0 +1,121 @@
+package interpreter
+
+import (
+	"github.com/ElaraLang/elara/parserlegacy"
+)
+
+//TypeAlias is a type declared with `type Name = ...`.
+//It is resolved lazily, so that aliases can refer to themselves and to aliases declared after them.
+type TypeAlias struct {
+	name      string
+	contract  parserlegacy.Type
+	ctx       *Context
+	resolved  Type
+	resolving bool
+	arguments []Type //The type arguments of an instance of a GenericTypeAlias
+}
+
+func NewTypeAlias(name string, contract parserlegacy.Type, ctx *Context) *TypeAlias {
+	return &TypeAlias{name: name, contract: contract, ctx: ctx}
+}
+
+func (t *TypeAlias) Name() string {
+	return t.name
+}
+
+//Accepts checks the aliased type. A recursive type accepts another type if no part of it disagrees,
+//so a check that is already in progress in ctx's acceptances is assumed to succeed.
+func (t *TypeAlias) Accepts(otherType Type, ctx *Context) bool {
+	if t == otherType {
+		return true
+	}
+	if ctx.acceptances == nil {
+		ctx.acceptances = map[[2]Type]bool{} //A context that wasn't created by an Interpreter
+	}
+	pending := [2]Type{t, otherType}
+	if ctx.acceptances[pending] {
+		return true
+	}
+	ctx.acceptances[pending] = true
+	defer delete(ctx.acceptances, pending)
+	if otherAlias, isAlias := otherType.(*TypeAlias); isAlias {
+		otherType = otherAlias.resolve()
+	}
+	return t.resolve().Accepts(otherType, ctx)
+}
+
+//resolve returns the aliased type. References to the alias from within its own definition are left as the alias itself.
+func (t *TypeAlias) resolve() Type {
+	if t.resolved == nil {
+		t.resolving = true
+		defer func() { t.resolving = false }() //Reset even if the contract cannot be resolved, so the alias is not left half resolved
+		resolved := FromASTType(t.contract, t.ctx)
+		if defined, isDefined := resolved.(*DefinedType); isDefined && defined.name == "" {
+			defined.name = t.name
+		}
+		t.resolved = resolved
+	}
+	return t.resolved
+}
+
+//unalias resolves t if it is an alias that is not currently being resolved
+func unalias(t Type) Type {
+	alias, isAlias := t.(*TypeAlias)
+	if !isAlias || alias.resolving {
+		return t
+	}
+	return alias.resolve()
+}
+
+//GenericTypeAlias is a type alias with type parameters, such as `type Pair<A, B> = { A first, B second }`
+type GenericTypeAlias struct {
+	name           string
+	TypeParameters []*TypeParameter
+	contract       parserlegacy.Type
+	ctx            *Context
+	instances      []*TypeAlias
+}
+
+func NewGenericTypeAlias(name string, typeParameters []*TypeParameter, contract parserlegacy.Type, ctx *Context) *GenericTypeAlias {
+	return &GenericTypeAlias{
+		name:           name,
+		TypeParameters: typeParameters,
+		contract:       contract,
+		ctx:            ctx,
+	}
+}
+
+func (t *GenericTypeAlias) Name() string {
+	return t.name + typeParametersString(t.TypeParameters)
+}
+
+//A generic alias without type arguments accepts anything that some instantiation of it would accept
+func (t *GenericTypeAlias) Accepts(otherType Type, ctx *Context) bool {
+	arguments := make([]Type, len(t.TypeParameters))
+	for i, parameter := range t.TypeParameters {
+		arguments[i] = parameter.Contract
+	}
+	return t.Instantiate(arguments, ctx).Accepts(otherType, ctx)
+}
+
+//Instantiate resolves the alias with its type parameters bound to typeArguments.
+//Instances are cached, so a recursive alias such as List<T> refers back to the same instance.
+func (t *GenericTypeAlias) Instantiate(typeArguments []Type, _ *Context) Type {
+	if len(typeArguments) != len(t.TypeParameters) {
+		panic("Type " + t.name + " expects " + typeParametersString(t.TypeParameters) + ", received " + typeArgumentsString(typeArguments))
+	}
+	for _, instance := range t.instances {
+		if sameTypes(instance.arguments, typeArguments) {
+			return unalias(instance)
+		}
+	}
+	typeScope := t.ctx.EnterScope(t.ctx.name, t.ctx.function, 0)
+	for i, parameter := range t.TypeParameters {
+		parameter.check(typeArguments[i], t.ctx)
+		typeScope.types[parameter.name] = typeArguments[i]
+	}
+	instance := NewTypeAlias(t.name+typeArgumentsString(typeArguments), t.contract, typeScope)
+	instance.arguments = typeArguments
+	t.instances = append(t.instances, instance)
+	return unalias(instance)
+}
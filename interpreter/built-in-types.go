@@ -7,6 +7,8 @@ import (
 
 var AnyType = NewEmptyType("Any")
 var UnitType = NewEmptyType("Unit")
+var NullType = NewEmptyType("Null")
+var NothingType = NewEmptyType("Nothing") //The type of a value that can never exist, such as the error of an Ok result
 
 var FloatType = NewEmptyType("Float")
 var BooleanType = NewEmptyType("Boolean")
@@ -19,12 +21,21 @@ var OutputType = NewEmptyType("Output")
 var types = []Type{
 	AnyType,
 	UnitType,
+	NullType,
+	NothingType,
 	IntType,
 	FloatType,
 	BooleanType,
 	StringType,
 	CharType,
 	OutputType,
+	ErrorType,
+	TypeErrorType,
+	NameErrorType,
+	NullErrorType,
+	IndexErrorType,
+	IOErrorType,
+	MatchErrorType,
 }
 
 func Init(context *Context) {
@@ -32,8 +43,10 @@ func Init(context *Context) {
 		context.types[t.Name()] = t
 	}
 	context.types["String"] = StringType
+	context.types["Result"] = AnyResultType
 
 	InitInts(context)
+	InitResults(context)
 
 	stringPlusName := "plus"
 	stringPlus := &Function{
@@ -55,10 +68,7 @@ func Init(context *Context) {
 			this := ctx.FindParameter(0)
 			otherParam := ctx.FindParameter(1)
 			concatenated := this.Value.(*Collection).elemsAsString() + util.Stringify(otherParam.Value)
-			return NonReturningValue(&Value{
-				Type:  StringType,
-				Value: concatenated,
-			})
+			return NonReturningValue(StringValue(concatenated))
 		}),
 		name: &stringPlusName,
 	}
@@ -93,10 +103,7 @@ func Init(context *Context) {
 			otherParam := ctx.FindParameter(1)
 
 			concatenated := ctx.Stringify(this) + otherParam.Value.(*Collection).elemsAsString()
-			return NonReturningValue(&Value{
-				Type:  StringType,
-				Value: concatenated,
-			})
+			return NonReturningValue(StringValue(concatenated))
 		}),
 		name: &anyPlusName,
 	}
@@ -111,6 +118,28 @@ func Init(context *Context) {
 		},
 	})
 
+	//String + String would otherwise be ambiguous between the String, Any and collection overloads
+	define(context, "plus", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "this",
+					Type: StringType,
+				},
+				{
+					Name:     "other",
+					Type:     StringType,
+					Position: 1,
+				}},
+			ReturnType: StringType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			this := ctx.FindParameter(0).Value.(*Collection)
+			other := ctx.FindParameter(1).Value.(*Collection)
+			return NonReturningValue(StringValue(this.elemsAsString() + other.elemsAsString()))
+		}),
+	})
+
 	define(context, "toString", &Function{
 		Signature: Signature{
 			Parameters: []Parameter{
@@ -273,14 +302,20 @@ func Init(context *Context) {
 		},
 		name: &anyEqualsName,
 		Body: NewAbstractCommand(func(c *Context) *ReturnedValue {
-			this := c.FindParameter(0).Value
+			thisParam := c.FindParameter(0)
+			this := thisParam.Value
 			other := c.FindParameter(1)
+			if thisParam.IsNull() || other.IsNull() {
+				return NonReturningValue(BooleanValue(thisParam.IsNull() && other.IsNull()))
+			}
 			value := false
 			switch a := this.(type) {
 			case *Collection:
 				value = a.Equals(c, other)
 			case *Instance:
 				value = a.Equals(c, other)
+			case *Result:
+				value = a.Equals(c, other)
 			case int64:
 				asI64, isI64 := other.Value.(int64)
 				if isI64 && a == asI64 {
@@ -293,7 +328,7 @@ func Init(context *Context) {
 				}
 			}
 			if value == false {
-				value = this == other
+				value = this == other.Value
 			}
 			return NonReturningValue(BooleanValue(value))
 		}),
@@ -0,0 +1,175 @@
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//Opcode is the first byte of every bytecode instruction. It is followed by its operands, each of which is 2 bytes.
+type Opcode byte
+
+const (
+	OpConstant     Opcode = iota //Pushes constants[k]
+	OpNil                        //Pushes the nil result of a statement
+	OpUnit                       //Pushes Unit
+	OpPop                        //Discards the top of the stack
+	OpLoad                       //Pushes the value of the VariableCommand at commands[k]
+	OpCheckDefine                //Checks that the DefineVarCommand at commands[k] may define its variable, pushing whether it is an overload
+	OpDefine                     //Pops a value and the result of OpCheckDefine, and defines the variable of the DefineVarCommand at commands[k]
+	OpAssignTarget               //Finds the variable that the AssignmentCommand at commands[k] assigns, or jumps to address a if it assigns a property of the receiver instead
+	OpAssign                     //Pops a value and assigns it to the variable found by the last OpAssignTarget
+	OpJump                       //Jumps to address a
+	OpJumpIfFalse                //Pops a condition, jumping to address a if it is false. A condition that isn't a Boolean panics with conditionErrors[m].
+	OpInvoke                     //Pops n arguments and calls the InvocationCommand at commands[k] with them
+	OpInvokeOn                   //Pops a receiver and then n arguments, and calls the InvocationCommand at commands[k] on the receiver
+	OpExec                       //Executes the Command at commands[k] with the tree walker, returning from the chunk if it returns
+	OpReturn                     //Pops a value and returns it from the chunk
+	OpNoBranch                   //Throws the error for an if expression without an else branch
+	OpNotEquals                  //Negates the Boolean result of an equals call
+	OpProperty                   //Replaces a receiver with the property named by the ContextCommand at commands[k]
+	OpBoolean                    //Checks that the top of the stack is a Boolean operand of booleanOperators[m]
+	OpNot                        //Negates the Boolean checked by OpBoolean
+	OpPropagate                  //Unwraps an Ok result, or returns an Err result from the chunk
+	OpCollection                 //Pops n elements and pushes a collection of them
+	OpAccess                     //Pops an index and then a collection or map, and pushes its element at the index
+)
+
+//opcodeInfo is how an Opcode is written by the disassembler, and how many operands it takes
+var opcodeInfo = [...]struct {
+	name     string
+	operands int
+}{
+	OpConstant:     {"CONSTANT", 1},
+	OpNil:          {"NIL", 0},
+	OpUnit:         {"UNIT", 0},
+	OpPop:          {"POP", 0},
+	OpLoad:         {"LOAD", 1},
+	OpCheckDefine:  {"CHECK_DEFINE", 1},
+	OpDefine:       {"DEFINE", 1},
+	OpAssignTarget: {"ASSIGN_TARGET", 2},
+	OpAssign:       {"ASSIGN", 1},
+	OpJump:         {"JUMP", 1},
+	OpJumpIfFalse:  {"JUMP_IF_FALSE", 2},
+	OpInvoke:       {"INVOKE", 2},
+	OpInvokeOn:     {"INVOKE_ON", 2},
+	OpExec:         {"EXEC", 1},
+	OpReturn:       {"RETURN", 0},
+	OpNoBranch:     {"NO_BRANCH", 0},
+	OpNotEquals:    {"NOT_EQUALS", 0},
+	OpProperty:     {"PROPERTY", 1},
+	OpBoolean:      {"BOOLEAN", 1},
+	OpNot:          {"NOT", 0},
+	OpPropagate:    {"PROPAGATE", 0},
+	OpCollection:   {"COLLECTION", 1},
+	OpAccess:       {"ACCESS", 0},
+}
+
+//conditionErrors are the errors for conditions that aren't Booleans, which differ between if and while like they do in the tree walker
+var conditionErrors = []string{
+	"If statements requires boolean value",
+	"If statements requires boolean condition",
+}
+
+//booleanOperators are the operators whose operands OpBoolean checks
+var booleanOperators = []string{"&&", "||", "!"}
+
+const (
+	operatorAnd = iota
+	operatorOr
+	operatorNot
+)
+
+/*
+Chunk is a compiled sequence of bytecode instructions, along with the tables that their operands index into.
+Commands that have no instructions of their own are kept in the commands table and executed by the tree walker,
+so that a Chunk always behaves the same as the Command it was compiled from.
+A Chunk is itself a Command, so the compiled body of a function is called in the same way as any other.
+*/
+type Chunk struct {
+	name      string
+	code      []byte
+	constants []*Value
+	commands  []Command
+	children  []*Chunk //The chunks compiled from function bodies and other commands inside this one
+	maxDepth  int      //The most values that are on the stack at once
+}
+
+//operand reads the 2 byte operand at offset
+func (c *Chunk) operand(offset int) int {
+	return int(c.code[offset])<<8 | int(c.code[offset+1])
+}
+
+//Disassemble returns a readable listing of the instructions in the chunk, followed by those of every chunk inside it
+func (c *Chunk) Disassemble() string {
+	builder := &strings.Builder{}
+	c.disassemble(builder)
+	return builder.String()
+}
+
+func (c *Chunk) disassemble(builder *strings.Builder) {
+	builder.WriteString("== " + c.name + " ==\n")
+	for offset := 0; offset < len(c.code); {
+		op := Opcode(c.code[offset])
+		info := opcodeInfo[op]
+		operands := make([]int, info.operands)
+		for i := range operands {
+			operands[i] = c.operand(offset + 1 + i*2)
+		}
+		line := fmt.Sprintf("%04d %-20s", offset, info.name)
+		for _, operand := range operands {
+			line += fmt.Sprintf(" %4d", operand)
+		}
+		if comment := c.describe(op, operands); comment != "" {
+			line += "  ; " + comment
+		}
+		builder.WriteString(strings.TrimRight(line, " ") + "\n")
+		offset += 1 + info.operands*2
+	}
+	for _, child := range c.children {
+		builder.WriteString("\n")
+		child.disassemble(builder)
+	}
+}
+
+//describe explains what the operands of an instruction refer to
+func (c *Chunk) describe(op Opcode, operands []int) string {
+	switch op {
+	case OpConstant:
+		return c.constants[operands[0]].String()
+	case OpLoad, OpCheckDefine, OpDefine, OpAssignTarget, OpAssign, OpInvoke, OpInvokeOn, OpExec, OpProperty:
+		return describeCommand(c.commands[operands[0]])
+	case OpJumpIfFalse:
+		return conditionErrors[operands[1]]
+	case OpBoolean:
+		return booleanOperators[operands[0]]
+	}
+	return ""
+}
+
+func describeCommand(command Command) string {
+	switch command := command.(type) {
+	case *VariableCommand:
+		return command.Variable
+	case *DefineVarCommand:
+		return command.Name
+	case *AssignmentCommand:
+		return command.Name
+	case *ContextCommand:
+		return command.variable
+	case *InvocationCommand:
+		switch invoking := command.Invoking.(type) {
+		case *VariableCommand:
+			return invoking.Variable
+		case *ContextCommand:
+			return invoking.variable
+		}
+		return "<" + describeCommand(command.Invoking) + ">"
+	case *FunctionLiteralCommand:
+		if command.name != nil {
+			return "function " + *command.name
+		}
+		return "lambda"
+	}
+	return strings.TrimPrefix(reflect.TypeOf(command).String(), "*interpreter.")
+}
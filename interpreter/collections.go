@@ -78,7 +78,7 @@ func (t *Collection) Equals(ctx *Context, other *Value) bool {
 	for i, element := range t.Elements {
 		otherElem := otherAsCol.Elements[i]
 		if !element.Equals(ctx, otherElem) {
-			break
+			return false
 		}
 	}
 	return true
@@ -1,12 +1,12 @@
 package interpreter
 
 import (
-	"fmt"
 	"github.com/ElaraLang/elara/lexer"
 	"github.com/ElaraLang/elara/parserlegacy"
 	"github.com/ElaraLang/elara/util"
 	_ "github.com/ElaraLang/elara/util"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -22,6 +22,7 @@ type DefineVarCommand struct {
 	runtimeType Type
 
 	hashedName uint64
+	address    *address //The slot given by the Resolver, or nil if it was not resolved
 }
 
 func (c *DefineVarCommand) getType(ctx *Context) Type {
@@ -35,37 +36,70 @@ func (c *DefineVarCommand) getType(ctx *Context) Type {
 }
 
 func (c *DefineVarCommand) Exec(ctx *Context) *ReturnedValue {
+	var value *Value
+	overload := c.checkRedefinition(ctx)
+	if overload {
+		value = c.value.Exec(ctx).Unwrap()
+	} else {
+		returned := c.value.Exec(ctx)
+		if returned.IsReturning {
+			return returned //An early return from the enclosing function, such as from the ? operator
+		}
+		value = returned.Value
+	}
+	c.define(ctx, value, overload)
+	return NilValue()
+}
+
+//checkRedefinition panics if the variable already exists in this scope, unless it is a function that may be overloaded.
+//It returns whether the variable is an overload, as they are checked against each other once the value is known.
+func (c *DefineVarCommand) checkRedefinition(ctx *Context) bool {
 	if c.hashedName == 0 {
 		c.hashedName = util.Hash(c.Name)
 	}
-	var value *Value
-	foundVar, _ := ctx.FindVariableMaxDepth(c.hashedName, 1)
-	if foundVar != nil {
-		asFunction, isFunction := foundVar.Value.Value.(*Function)
-		if isFunction {
-			value = c.value.Exec(ctx).Unwrap()
-			valueAsFunction, valueIsFunction := value.Value.(*Function)
-			if valueIsFunction && !asFunction.Signature.Accepts(&valueAsFunction.Signature, ctx, false) {
-				//We'll allow it because the functions have different arity
-			} else {
-				//panic("Variable named " + c.Name + " already exists with the current signature") //TODO this might need to come back, maybe.
-			}
-		} else {
+	if c.address != nil {
+		if ctx.findResolvedVariable(c.address) != nil {
 			panic("Variable named " + c.Name + " already exists")
 		}
+		return false //Overloaded functions are not given slots
 	}
-	if value == nil {
-		value = c.value.Exec(ctx).Unwrap()
+	var foundVar *Variable
+	if ctx.block {
+		if defined := ctx.variables[c.hashedName]; defined != nil {
+			foundVar = defined[len(defined)-1] //Variables outside of a block may be shadowed
+		}
+	} else {
+		foundVar, _ = ctx.FindVariableMaxDepth(c.hashedName, 1)
+	}
+	if foundVar == nil {
+		return false
+	}
+	if _, isFunction := foundVar.Value.Value.(*Function); !isFunction {
+		panic("Variable named " + c.Name + " already exists")
 	}
+	return true
+}
 
+//define checks value against the declared type of the variable, and then defines it
+func (c *DefineVarCommand) define(ctx *Context, value *Value, overload bool) {
 	if value == nil {
 		panic("Command " + reflect.TypeOf(c.value).String() + " returned nil")
 	}
+	if valueAsFunction, valueIsFunction := value.Value.(*Function); overload && valueIsFunction {
+		//Overloads are allowed, but not if calls could never choose between them
+		for _, existing := range ctx.variables[c.hashedName] {
+			existingFunction, isFunction := existing.Value.Value.(*Function)
+			if isFunction && existingFunction.Signature.equivalent(&valueAsFunction.Signature, ctx) {
+				panic("Function " + c.Name + " is already defined with the signature " + existingFunction.Signature.String())
+			}
+		}
+	}
 
 	variableType := c.getType(ctx)
 	if variableType != nil {
+		value = inferParameters(value, variableType, ctx)
 		if !variableType.Accepts(value.Type, ctx) {
-			panic("Cannot use value of type " + value.Type.Name() + " in place of " + variableType.Name() + " for variable " + c.Name)
+			throw(ctx, TypeErrorType, "Cannot use value of type "+value.Type.Name()+" in place of "+variableType.Name()+" for variable "+c.Name+DescribeMismatch(variableType, value.Type, ctx))
 		}
 	} else {
 		variableType = value.Type
@@ -77,8 +111,12 @@ func (c *DefineVarCommand) Exec(ctx *Context) *ReturnedValue {
 		Value:   value,
 	}
 
+	if c.address != nil {
+		ctx.defineResolved(c.address, variable)
+		return
+	}
 	ctx.DefineVariable(variable)
-	return NilValue()
+	delete(ctx.uninitialised, c.hashedName)
 }
 
 type AssignmentCommand struct {
@@ -86,29 +124,130 @@ type AssignmentCommand struct {
 	value Command
 
 	hashedName uint64
+	address    *address
 }
 
+//Exec assigns the variable. Assignments are statements, so they evaluate to Unit rather than the assigned value.
 func (c *AssignmentCommand) Exec(ctx *Context) *ReturnedValue {
+	variable := c.target(ctx)
+	if variable == nil {
+		return c.assignReceiverProperty(ctx)
+	}
+	returned := c.value.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	c.assign(ctx, variable, returned.Value)
+	return NilValue()
+}
+
+//target finds the variable being assigned and checks that it can be reassigned.
+//It returns nil if the assignment is to a property of the receiver instead, which is done by assignReceiverProperty.
+func (c *AssignmentCommand) target(ctx *Context) *Variable {
 	if c.hashedName == 0 {
 		c.hashedName = util.Hash(c.Name)
 	}
-	variable := ctx.FindVariable(c.hashedName)
+	var variable *Variable
+	if c.address != nil {
+		variable = ctx.findResolvedVariable(c.address)
+		if variable == nil {
+			throw(ctx, NameErrorType, "Variable "+c.Name+" was assigned before it was initialised")
+		}
+	} else {
+		if c.receiverProperty(ctx) != nil {
+			return nil
+		}
+		variable = ctx.FindVariable(c.hashedName)
+	}
 	if variable == nil {
-		panic("No such variable " + c.Name)
+		throw(ctx, NameErrorType, "No such variable "+c.Name)
 	}
 
 	if !variable.Mutable {
-		panic("Cannot reassign immutable variable " + c.Name)
+		throw(ctx, TypeErrorType, "Cannot reassign immutable variable "+c.Name)
 	}
+	return variable
+}
 
-	value := c.value.Exec(ctx).Unwrap()
+//receiverProperty returns the receiver if the assignment is to an unqualified property of it inside an extension
+func (c *AssignmentCommand) receiverProperty(ctx *Context) *Value {
+	if receiver := ctx.findReceiver(); receiver != nil && ctx.findLocalVariable(c.hashedName) == nil {
+		if instance, isInstance := receiver.Value.(*Instance); isInstance {
+			if _, isProperty := instance.Type.GetProperty(c.Name); isProperty {
+				return receiver
+			}
+		}
+	}
+	return nil
+}
+
+func (c *AssignmentCommand) assignReceiverProperty(ctx *Context) *ReturnedValue {
+	return (&PropertyAssignmentCommand{
+		receiver: &LiteralCommand{value: c.receiverProperty(ctx)},
+		property: c.Name,
+		value:    c.value,
+	}).Exec(ctx)
+}
+
+//assign checks the type of value and then assigns it to variable
+func (c *AssignmentCommand) assign(ctx *Context, variable *Variable, value *Value) {
+	value = inferParameters(value, variable.Type, ctx)
 
 	if !variable.Type.Accepts(value.Type, ctx) {
-		panic("Cannot reassign variable " + c.Name + " of type " + variable.Type.Name() + " to value " + value.String() + " of type " + value.Type.Name())
+		throw(ctx, TypeErrorType, "Cannot reassign variable "+c.Name+" of type "+variable.Type.Name()+" to value "+value.String()+" of type "+value.Type.Name()+DescribeMismatch(variable.Type, value.Type, ctx))
 	}
 
 	variable.Value = value
-	return NonReturningValue(value)
+}
+
+type PropertyAssignmentCommand struct {
+	receiver Command
+	property string
+	value    Command
+	operator string //The operator function that a compound assignment applies to the current value of the property and value, or empty
+}
+
+//Exec assigns the property. Like variable assignments, it evaluates to Unit.
+func (c *PropertyAssignmentCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.receiver.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	receiver := returned.Value
+	if receiver.IsNull() {
+		throw(ctx, NullErrorType, "Cannot assign property "+c.property+" of null")
+	}
+	instance, isInstance := receiver.Value.(*Instance)
+	if !isInstance {
+		throw(ctx, TypeErrorType, "Cannot assign property "+c.property+" of non struct value "+receiver.String()+" of type "+receiver.Type.Name())
+	}
+	property, exists := instance.Type.GetProperty(c.property)
+	if !exists {
+		throw(ctx, NameErrorType, "No such property "+c.property+" on type "+instance.Type.Name())
+	}
+	if property.Modifiers&Mut == 0 {
+		throw(ctx, TypeErrorType, "Cannot reassign immutable property "+c.property+" of type "+instance.Type.Name())
+	}
+
+	current := instance.Values[c.property]
+	returned = c.value.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	if c.operator != "" {
+		returned = (&InvocationCommand{
+			Invoking: &ContextCommand{receiver: &LiteralCommand{value: current}, variable: c.operator},
+			args:     []Command{&LiteralCommand{value: returned.Value}},
+		}).Exec(ctx)
+	}
+	value := inferParameters(returned.Value, property.Type, ctx)
+
+	if !property.Type.Accepts(value.Type, ctx) {
+		throw(ctx, TypeErrorType, "Cannot reassign property "+c.property+" of type "+property.Type.Name()+" to value "+value.String()+" of type "+value.Type.Name()+DescribeMismatch(property.Type, value.Type, ctx))
+	}
+
+	instance.Values[c.property] = value
+	return NilValue()
 }
 
 type VariableCommand struct {
@@ -116,20 +255,37 @@ type VariableCommand struct {
 
 	hash      uint64
 	cachedVar *Value
+	address   *address
 }
 
 func (c *VariableCommand) findVariable(ctx *Context) *Variable {
 	if c.hash == 0 {
 		c.hash = util.Hash(c.Variable)
 	}
-
-	variable := ctx.FindVariable(c.hash)
-	return variable
+	if c.address != nil {
+		if c.address.parameter {
+			return nil
+		}
+		return ctx.findResolvedVariable(c.address)
+	}
+	return ctx.FindVariable(c.hash)
 }
 
 func (c *VariableCommand) Exec(ctx *Context) *ReturnedValue {
+	return NonReturningValue(c.value(ctx))
+}
+
+//value returns the value of the variable, parameter or constructor that the command names
+func (c *VariableCommand) value(ctx *Context) *Value {
 	if c.cachedVar != nil {
-		return NonReturningValue(c.cachedVar)
+		return c.cachedVar
+	}
+	if c.address != nil {
+		value := ctx.findResolved(c.address)
+		if value == nil {
+			throw(ctx, NameErrorType, "Variable "+c.Variable+" was read before it was initialised")
+		}
+		return value
 	}
 	paramIndex := -1
 	fun := ctx.function
@@ -144,23 +300,40 @@ func (c *VariableCommand) Exec(ctx *Context) *ReturnedValue {
 	if paramIndex != -1 {
 		param := ctx.FindParameter(uint(paramIndex))
 		if param != nil {
-			return NonReturningValue(param)
+			return param
+		}
+	}
+	if receiver := ctx.findReceiver(); receiver != nil {
+		//Inside an extension, locals shadow the receiver's members, which shadow everything else
+		if c.hash == 0 {
+			c.hash = util.Hash(c.Variable)
+		}
+		local := ctx.findLocalVariable(c.hash)
+		if local != nil {
+			return local.Value
+		}
+		member := ctx.findReceiverMember(receiver, c.Variable)
+		if member != nil {
+			return member
 		}
 	}
 	variable := c.findVariable(ctx)
 	if variable != nil {
-		return NonReturningValue(variable.Value)
+		return variable.Value
 	}
 
 	constructor := ctx.FindConstructor(c.Variable)
 	if constructor == nil {
 		if ctx.function != nil && ctx.function.context != nil {
-			return c.Exec(ctx.function.context)
+			return c.value(ctx.function.context)
+		}
+		if ctx.isUninitialised(c.hash) {
+			throw(ctx, NameErrorType, "Variable "+c.Variable+" was read before it was initialised")
 		}
-		panic("No such variable or parameter or constructor " + c.Variable)
+		throw(ctx, NameErrorType, "No such variable or parameter or constructor "+c.Variable)
 	}
 	c.cachedVar = constructor
-	return NonReturningValue(constructor)
+	return constructor
 }
 
 type InvocationCommand struct {
@@ -168,24 +341,14 @@ type InvocationCommand struct {
 	args     []Command
 
 	cachedFun *Function
+	receivers receiverCache //The extensions called on each type of receiver, when invoking a function on a receiver
+	tail      bool          //The value of the invocation is returned from the function it is in
 }
 
 func (c *InvocationCommand) findReceiverFunction(ctx *Context, receiver *Value, argValues []*Value, functionName string, nameHash uint64) *Function {
 	receiverType := receiver.Type
-	parameters := []Parameter{{
-		Name: "this",
-		Type: receiverType,
-	}}
-	for i, value := range argValues {
-		parameters = append(parameters, Parameter{
-			Name: fmt.Sprintf("<param%d>", i),
-			Type: value.Type,
-		})
-	}
-	receiverSignature := &Signature{
-		Parameters: parameters,
-		ReturnType: AnyType, //can't infer this rn
-	}
+	receiverSignature := argumentSignature(append([]*Value{receiver}, argValues...))
+	receiverSignature.Parameters[0].Name = "this"
 
 	receiverFunction := ctx.FindFunction(nameHash, receiverSignature)
 	if receiverFunction == nil {
@@ -199,44 +362,70 @@ func (c *InvocationCommand) findReceiverFunction(ctx *Context, receiver *Value,
 	return receiverFunction
 }
 func (c *InvocationCommand) Exec(ctx *Context) *ReturnedValue {
-	context, usingReceiver := c.Invoking.(*ContextCommand)
-
 	argValues := make([]*Value, len(c.args))
 	for i, arg := range c.args {
-		argValues[i] = arg.Exec(ctx).UnwrapNotNil()
+		returned := arg.Exec(ctx)
+		if returned.IsReturning {
+			return returned
+		}
+		argValues[i] = returned.UnwrapNotNil()
 	}
 
+	context, usingReceiver := c.Invoking.(*ContextCommand)
 	if !usingReceiver {
-		if c.cachedFun != nil {
-			return NonReturningValue(c.cachedFun.Exec(ctx, argValues)) //Avoid unnecessary lookup
-		}
-		val := c.Invoking.Exec(ctx).Unwrap()
-		fun, ok := val.Value.(*Function)
-		if !ok {
-			panic("Cannot invoke value that isn't a function ")
+		return NonReturningValue(c.invoke(ctx, argValues))
+	}
+	returned := context.receiver.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return NonReturningValue(c.invokeOn(ctx, context, returned.Value, argValues))
+}
+
+//invoke calls the function that the command's Invoking evaluates to, once the arguments have been evaluated
+func (c *InvocationCommand) invoke(ctx *Context, argValues []*Value) *Value {
+	if c.cachedFun != nil {
+		return c.call(ctx, c.cachedFun, argValues) //Avoid unnecessary lookup
+	}
+	val := c.Invoking.Exec(ctx).Unwrap()
+	fun, ok := val.Value.(*Function)
+	if !ok {
+		panic("Cannot invoke value that isn't a function ")
+	}
+	switch t := c.Invoking.(type) {
+	case *VariableCommand:
+		variable := t.findVariable(ctx)
+		if variable == nil || variable.Value != val {
+			break //A parameter or receiver member rather than a variable
 		}
-		switch t := c.Invoking.(type) {
-		case *VariableCommand:
-			variable := t.findVariable(ctx)
-			if variable != nil && !variable.Mutable {
-				c.cachedFun = fun
+		if t.address == nil && ctx.IsOverloaded(t.hash) { //Overloaded functions are not given slots
+			overload := ctx.FindFunction(t.hash, argumentSignature(argValues))
+			if overload != nil {
+				fun = overload
 			}
+			break //The overload depends on the arguments, so can't be cached
+		}
+		if !variable.Mutable && t.address == nil && ctx.findReceiver() == nil { //Local functions and receiver members are bound per call
+			c.cachedFun = fun
 		}
-
-		return NonReturningValue(fun.Exec(ctx, argValues))
 	}
 
-	//ContextCommand seems to think it's a special case... because it is.
-	var receiver *Value
+	return c.call(ctx, fun, argValues)
+}
 
+//invokeOn calls the function named by context on receiver, once the arguments and then the receiver have been evaluated.
+//ContextCommand seems to think it's a special case... because it is.
+func (c *InvocationCommand) invokeOn(ctx *Context, context *ContextCommand, receiver *Value, argValues []*Value) *Value {
 	functionName := context.variable
 
-	receiver = context.receiver.Exec(ctx).Unwrap()
+	if receiver.IsNull() && context.nullSafe {
+		return NullValue()
+	}
 
-	if c.cachedFun != nil {
+	if cached := c.receivers.find(receiver.Type); cached != nil {
 		argValuesAndSelf := []*Value{receiver}
 		argValuesAndSelf = append(argValuesAndSelf, argValues...)
-		return NonReturningValue(c.cachedFun.Exec(ctx, argValuesAndSelf))
+		return c.call(ctx, cached, argValuesAndSelf)
 	}
 
 	structType, isStruct := receiver.Type.(*StructType)
@@ -248,31 +437,33 @@ func (c *InvocationCommand) Exec(ctx *Context) *ReturnedValue {
 			if !ok {
 				panic("Cannot invoke non-function " + value.Name)
 			}
-			this := context.receiver.Exec(ctx).Unwrap()
-
-			argValues := make([]*Value, len(c.args))
-			argValues = append(argValues, this)
-			for i, arg := range c.args {
-				argValues[i] = arg.Exec(ctx).Unwrap()
-			}
-			return NonReturningValue(function.Exec(ctx, argValues))
+			argValues = append(argValues, receiver)
+			return c.call(ctx, function, argValues)
 		}
 	}
 
 	extension := ctx.FindExtension(receiver.Type, functionName)
 	if extension != nil {
 		fun := extension.Value.Value.Value.(*Function)
-		c.cachedFun = fun
+		c.receivers.add(receiver.Type, fun)
 		argValuesAndSelf := []*Value{receiver}
 		argValuesAndSelf = append(argValuesAndSelf, argValues...)
-		return NonReturningValue(fun.Exec(ctx, argValuesAndSelf))
+		return c.call(ctx, fun, argValuesAndSelf)
 	}
 
 	//Look for a receiver
 	receiverFunction := c.findReceiverFunction(ctx, receiver, argValues, functionName, context.hash())
 	argValuesAndSelf := []*Value{receiver}
 	argValuesAndSelf = append(argValuesAndSelf, argValues...)
-	return NonReturningValue(receiverFunction.Exec(ctx, argValuesAndSelf))
+	return c.call(ctx, receiverFunction, argValuesAndSelf)
+}
+
+//call calls fun, unless the invocation is a tail call, in which case the function it is in calls fun once it has returned
+func (c *InvocationCommand) call(ctx *Context, fun *Function, argValues []*Value) *Value {
+	if c.tail && fun.context != nil {
+		return &Value{Value: &tailCall{function: fun, arguments: argValues}}
+	}
+	return fun.Exec(ctx, argValues)
 }
 
 type AbstractCommand struct {
@@ -299,23 +490,36 @@ func (c *LiteralCommand) Exec(_ *Context) *ReturnedValue {
 
 type FunctionLiteralCommand struct {
 	name       *string
+	generics   []parserlegacy.GenericContract
 	parameters []parserlegacy.FunctionArgument
 	returnType parserlegacy.Type //Can be nil - infer return type
 	body       Command
-
-	currentContext *Context
+	frameSize  int
 }
 
 func (c *FunctionLiteralCommand) Exec(ctx *Context) *ReturnedValue {
-	if c.currentContext == nil {
-		c.currentContext = ctx.Clone()
-		//Function literals take a snapshot of their current context to avoid scoping issues
-		//This one will be cached forever, so we don't need to cleanup
+	captured := ctx.capture() //Every evaluation captures the environment it is evaluated in
+
+	typeContext := captured
+	var typeParameters []*TypeParameter
+	if len(c.generics) != 0 {
+		typeParameters = NewTypeParameters(c.generics, captured)
+		typeContext = captured.EnterTypeScope(typeParameters)
 	}
+
 	params := make([]Parameter, len(c.parameters))
 
 	for i, parameter := range c.parameters {
-		paramType := FromASTType(parameter.Type, c.currentContext)
+		if parameter.Type == nil {
+			params[i] = Parameter{
+				Type:     AnyType,
+				Name:     parameter.Name,
+				Position: uint(i),
+				untyped:  true,
+			}
+			continue
+		}
+		paramType := FromASTType(parameter.Type, typeContext)
 		params[i] = Parameter{
 			Type:     paramType,
 			Name:     parameter.Name,
@@ -328,17 +532,23 @@ func (c *FunctionLiteralCommand) Exec(ctx *Context) *ReturnedValue {
 	if astReturnType == nil {
 		returnType = AnyType
 	} else {
-		returnType = FromASTType(c.returnType, c.currentContext)
+		returnType = FromASTType(c.returnType, typeContext)
+	}
+	if typeContext != captured {
+		typeContext.Cleanup()
 	}
 
 	fun := &Function{
 		name: c.name,
 		Signature: Signature{
-			Parameters: params,
-			ReturnType: returnType,
+			TypeParameters:     typeParameters,
+			Parameters:         params,
+			ReturnType:         returnType,
+			returnTypeInferred: astReturnType == nil,
 		},
-		Body:    c.body,
-		context: c.currentContext,
+		Body:      c.body,
+		context:   captured,
+		frameSize: c.frameSize,
 	}
 
 	functionType := NewFunctionType(fun)
@@ -356,17 +566,33 @@ type BinaryOperatorCommand struct {
 }
 
 func (c *BinaryOperatorCommand) Exec(ctx *Context) *ReturnedValue {
-	lhs := c.lhs.Exec(ctx).Unwrap()
-	rhs := c.rhs.Exec(ctx).Unwrap()
-
-	return c.op(ctx, lhs, rhs)
+	lhs := c.lhs.Exec(ctx)
+	if lhs.IsReturning {
+		return lhs
+	}
+	rhs := c.rhs.Exec(ctx)
+	if rhs.IsReturning {
+		return rhs
+	}
+	return c.op(ctx, lhs.Value, rhs.Value)
 }
 
 type BlockCommand struct {
 	lines []*Command
+	scope *blockScope //nil if the block does not declare any variables of its own
 }
 
 func (c *BlockCommand) Exec(ctx *Context) *ReturnedValue {
+	if c.scope != nil {
+		scope := c.scope.enter(ctx)
+		result := c.run(scope)
+		scope.Cleanup()
+		return result
+	}
+	return c.run(ctx)
+}
+
+func (c *BlockCommand) run(ctx *Context) *ReturnedValue {
 	var last = NonReturningValue(UnitValue())
 	for _, lineRef := range c.lines {
 		line := *lineRef
@@ -379,10 +605,40 @@ func (c *BlockCommand) Exec(ctx *Context) *ReturnedValue {
 	return last
 }
 
+//blockScope is the scope opened by a block that declares variables, so that they are discarded when the block exits
+type blockScope struct {
+	frameSize int //The number of variables the Resolver gave slots in the block
+}
+
+func (s *blockScope) enter(ctx *Context) *Context {
+	scope := ctx.EnterBlockScope()
+	scope.frame = newFrame(s.frameSize, nil, ctx.frame)
+	return scope
+}
+
+//unscoped removes the scope of a block that is run in a scope of its own, such as the body of a function
+func unscoped(body Command) Command {
+	if block, isBlock := body.(*BlockCommand); isBlock {
+		block.scope = nil
+	}
+	return body
+}
+
+//scopeFor returns a scope for a block containing statements, or nil if none of them declare a variable
+func scopeFor(statements ...Command) *blockScope {
+	for _, statement := range statements {
+		if _, isDefinition := statement.(*DefineVarCommand); isDefinition {
+			return &blockScope{}
+		}
+	}
+	return nil
+}
+
 type ContextCommand struct {
 	receiver       Command
 	variable       string
 	hashedVariable uint64
+	nullSafe       bool //receiver?.variable evaluates to null if the receiver is null
 }
 
 func (c *ContextCommand) hash() uint64 {
@@ -395,14 +651,28 @@ func (c *ContextCommand) Exec(ctx *Context) *ReturnedValue {
 	if c.hashedVariable == 0 {
 		c.hashedVariable = util.Hash(c.variable)
 	}
-	receiver := c.receiver.Exec(ctx).Unwrap()
+	returned := c.receiver.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return NonReturningValue(c.property(ctx, returned.Value))
+}
+
+//property returns the property (or extension) of receiver that the command names
+func (c *ContextCommand) property(ctx *Context, receiver *Value) *Value {
+	if receiver.IsNull() {
+		if c.nullSafe {
+			return NullValue()
+		}
+		throw(ctx, NullErrorType, "Cannot access property "+c.variable+" of null. Use ?. for null safe access")
+	}
 
-	var value *ReturnedValue
+	var value *Value
 	switch val := receiver.Value.(type) {
 	case *Collection:
 		switch c.variable {
 		case "size":
-			value = NonReturningValue(IntValue(int64(len(val.Elements))))
+			value = IntValue(int64(len(val.Elements)))
 		}
 	case *Map:
 		switch c.variable {
@@ -417,10 +687,10 @@ func (c *ContextCommand) Exec(ctx *Context) *ReturnedValue {
 			}
 			collectionType := NewCollectionType(collection)
 
-			value = NonReturningValue(&Value{
+			value = &Value{
 				Type:  collectionType,
 				Value: collection,
-			})
+			}
 		case "values":
 			valueSet := make([]*Value, len(val.Elements))
 			for i, element := range val.Elements {
@@ -432,19 +702,19 @@ func (c *ContextCommand) Exec(ctx *Context) *ReturnedValue {
 			}
 			collectionType := NewCollectionType(collection)
 
-			value = NonReturningValue(&Value{
+			value = &Value{
 				Type:  collectionType,
 				Value: collection,
-			})
+			}
 		}
 	case *Instance:
 		{
-			value = NonReturningValue(val.Values[c.variable])
+			value = val.Values[c.variable]
 		}
 	default:
 		panic("Unsupported receiver " + util.Stringify(receiver))
 	}
-	if value != nil && value.Value != nil {
+	if value != nil {
 		return value
 	}
 
@@ -453,7 +723,149 @@ func (c *ContextCommand) Exec(ctx *Context) *ReturnedValue {
 	if extension == nil {
 		panic("Unknown property or extension for " + receiver.String() + " with name " + c.variable)
 	}
-	return NonReturningValue(extension.Value.Value)
+	return extension.Value.Value
+}
+
+//NotEqualsCommand evaluates lhs != rhs as the negation of lhs.equals(rhs)
+type NotEqualsCommand struct {
+	equals *InvocationCommand
+}
+
+func (c *NotEqualsCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.equals.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	asBool, ok := returned.Value.Value.(bool)
+	if !ok {
+		panic("equals function did not return bool")
+	}
+	return NonReturningValue(BooleanValue(!asBool))
+}
+
+//ElvisCommand evaluates lhs ?: rhs. rhs is only evaluated if lhs is null
+type ElvisCommand struct {
+	lhs Command
+	rhs Command
+}
+
+func (c *ElvisCommand) Exec(ctx *Context) *ReturnedValue {
+	lhs := c.lhs.Exec(ctx)
+	if lhs.IsReturning || !lhs.Value.IsNull() {
+		return lhs
+	}
+	return c.rhs.Exec(ctx)
+}
+
+//AndCommand evaluates lhs && rhs. rhs is only evaluated if lhs is true
+type AndCommand struct {
+	lhs Command
+	rhs Command
+}
+
+func (c *AndCommand) Exec(ctx *Context) *ReturnedValue {
+	lhs := c.lhs.Exec(ctx)
+	if lhs.IsReturning {
+		return lhs
+	}
+	if !booleanOperand(ctx, lhs.Value, "&&") {
+		return NonReturningValue(BooleanValue(false))
+	}
+	rhs := c.rhs.Exec(ctx)
+	if rhs.IsReturning {
+		return rhs
+	}
+	return NonReturningValue(BooleanValue(booleanOperand(ctx, rhs.Value, "&&")))
+}
+
+//OrCommand evaluates lhs || rhs. rhs is only evaluated if lhs is false
+type OrCommand struct {
+	lhs Command
+	rhs Command
+}
+
+func (c *OrCommand) Exec(ctx *Context) *ReturnedValue {
+	lhs := c.lhs.Exec(ctx)
+	if lhs.IsReturning {
+		return lhs
+	}
+	if booleanOperand(ctx, lhs.Value, "||") {
+		return NonReturningValue(BooleanValue(true))
+	}
+	rhs := c.rhs.Exec(ctx)
+	if rhs.IsReturning {
+		return rhs
+	}
+	return NonReturningValue(BooleanValue(booleanOperand(ctx, rhs.Value, "||")))
+}
+
+type NotCommand struct {
+	expression Command
+}
+
+func (c *NotCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.expression.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return NonReturningValue(BooleanValue(!booleanOperand(ctx, returned.Value, "!")))
+}
+
+func booleanOperand(ctx *Context, value *Value, operator string) bool {
+	asBool, ok := value.Value.(bool)
+	if !ok {
+		throw(ctx, TypeErrorType, "The "+operator+" operator can only be used on a Boolean, got "+value.Type.Name())
+	}
+	return asBool
+}
+
+type NotNullCommand struct {
+	expression Command
+}
+
+func (c *NotNullCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.expression.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	if returned.Value.IsNull() {
+		throw(ctx, NullErrorType, "Non-null assertion failed: value is null")
+	}
+	return returned
+}
+
+//PropagateCommand implements the postfix ? operator.
+//An Ok result is unwrapped, and an Err result is returned early from the enclosing function.
+type PropagateCommand struct {
+	expression Command
+}
+
+func (c *PropagateCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.expression.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return propagate(ctx, returned.Value)
+}
+
+//propagate unwraps an Ok result, or returns an Err result
+func propagate(ctx *Context, value *Value) *ReturnedValue {
+	result, isResult := value.Value.(*Result)
+	if !isResult {
+		throw(ctx, TypeErrorType, "The ? operator can only be used on a Result, got "+value.Type.Name())
+	}
+	if result.IsOk {
+		return NonReturningValue(result.Value)
+	}
+	if ctx.function == nil {
+		//There is no function to return from, so the error is thrown instead
+		errorType, isStruct := result.Value.Type.(*StructType)
+		if isStruct && errorType.isError() {
+			panic(&Thrown{Value: result.Value})
+		}
+		throw(ctx, ErrorType, ctx.Stringify(result.Value))
+	}
+	return ReturningValue(value)
 }
 
 type IfElseCommand struct {
@@ -464,6 +876,9 @@ type IfElseCommand struct {
 
 func (c *IfElseCommand) Exec(ctx *Context) *ReturnedValue {
 	condition := c.condition.Exec(ctx)
+	if condition.IsReturning {
+		return condition
+	}
 	value, ok := condition.Unwrap().Value.(bool)
 	if !ok {
 		panic("If statements requires boolean value")
@@ -484,30 +899,90 @@ type IfElseExpressionCommand struct {
 	ifResult   Command
 	elseBranch []Command
 	elseResult Command
+
+	ifScope   *blockScope //nil if the statements of the branch do not declare any variables
+	elseScope *blockScope
 }
 
 func (c *IfElseExpressionCommand) Exec(ctx *Context) *ReturnedValue {
 	condition := c.condition.Exec(ctx)
+	if condition.IsReturning {
+		return condition
+	}
 	value, ok := condition.Unwrap().Value.(bool)
 	if !ok {
 		panic("If statements requires boolean value")
 	}
 
 	if value {
-		if c.ifBranch != nil {
-			for _, cmd := range c.ifBranch {
-				cmd.Exec(ctx)
-			}
-		}
-		return c.ifResult.Exec(ctx)
+		return runBranch(ctx, c.ifScope, c.ifBranch, c.ifResult)
 	} else {
-		if c.elseBranch != nil {
-			for _, cmd := range c.elseBranch {
-				cmd.Exec(ctx)
-			}
+		return runBranch(ctx, c.elseScope, c.elseBranch, c.elseResult)
+	}
+}
+
+//runBranch runs the statements of a branch of an if expression, and then evaluates its result.
+//A branch without a result is the missing else branch.
+func runBranch(ctx *Context, scope *blockScope, statements []Command, result Command) *ReturnedValue {
+	if scope != nil {
+		ctx = scope.enter(ctx)
+		defer ctx.Cleanup()
+	}
+	for _, statement := range statements {
+		returned := statement.Exec(ctx)
+		if returned.IsReturning {
+			return returned
+		}
+	}
+	if result == nil {
+		throw(ctx, MatchErrorType, "No branch of the if expression matched")
+	}
+	return result.Exec(ctx)
+}
+
+type MatchCommand struct {
+	subject Command
+	arms    []matchArm
+}
+
+type matchArm struct {
+	checkType parserlegacy.Type //nil if the arm matches a value, or is an else arm
+	value     Command           //nil if the arm matches a type, or is an else arm
+	result    Command
+}
+
+func (c *MatchCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.subject.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	subject := returned.Value
+	for _, arm := range c.arms {
+		matches, returned := arm.matches(ctx, subject)
+		if returned != nil {
+			return returned
+		}
+		if matches {
+			return arm.result.Exec(ctx)
 		}
-		return c.elseResult.Exec(ctx)
 	}
+	throw(ctx, MatchErrorType, "No arm of the match matched "+ctx.Stringify(subject)+" of type "+subject.Type.Name())
+	return nil
+}
+
+//matches checks whether the arm matches subject, returning the value of its pattern instead if evaluating it returned early
+func (a *matchArm) matches(ctx *Context, subject *Value) (bool, *ReturnedValue) {
+	if a.checkType != nil {
+		return FromASTType(a.checkType, ctx).Accepts(subject.Type, ctx), nil
+	}
+	if a.value != nil {
+		returned := a.value.Exec(ctx)
+		if returned.IsReturning {
+			return false, returned
+		}
+		return subject.Equals(ctx, returned.Value), nil
+	}
+	return true, nil
 }
 
 type ReturnCommand struct {
@@ -518,7 +993,117 @@ func (c *ReturnCommand) Exec(ctx *Context) *ReturnedValue {
 	if c.returning == nil {
 		return ReturningValue(UnitValue())
 	}
-	return ReturningValue(c.returning.Exec(ctx).Unwrap())
+	returned := c.returning.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return ReturningValue(returned.Value)
+}
+
+type ThrowCommand struct {
+	error Command
+}
+
+func (c *ThrowCommand) Exec(ctx *Context) *ReturnedValue {
+	returned := c.error.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	value := returned.Value
+	errorType, isStruct := value.Type.(*StructType)
+	if !isStruct || !errorType.isError() {
+		//Throwing anything other than an error is shorthand for throwing an Error with it as the message
+		throw(ctx, ErrorType, ctx.Stringify(value))
+	}
+	instance := value.Value.(*Instance)
+	if len(instance.Values["stackTrace"].Value.(*Collection).Elements) == 0 {
+		instance.Values["stackTrace"] = ctx.calls.trace() //Rethrowing a caught error preserves its original trace
+	}
+	panic(&Thrown{Value: value})
+}
+
+type TryCommand struct {
+	body    Command
+	catches []*CatchCommand
+	finally Command //Can be nil
+}
+
+func (c *TryCommand) Exec(ctx *Context) *ReturnedValue {
+	if c.finally != nil {
+		defer c.finally.Exec(ctx)
+	}
+	result, thrown := c.execBody(ctx)
+	if thrown == nil {
+		return result
+	}
+	for _, catch := range c.catches {
+		if catch.catches(thrown.Value, ctx) {
+			return catch.Exec(ctx, thrown.Value)
+		}
+	}
+	panic(thrown)
+}
+
+func (c *TryCommand) execBody(ctx *Context) (result *ReturnedValue, thrown *Thrown) {
+	defer func() {
+		if r := recover(); r != nil {
+			thrown = toThrown(ctx, r)
+			if thrown == nil {
+				panic(r)
+			}
+		}
+	}()
+	return c.body.Exec(ctx), nil
+}
+
+type CatchCommand struct {
+	identifier string
+	errorType  parserlegacy.Type //Can be nil - catches any error
+	body       Command
+
+	address   *address //The slot of the caught error, or nil if the clause was not resolved
+	frameSize int
+}
+
+func (c *CatchCommand) catches(thrown *Value, ctx *Context) bool {
+	if c.errorType == nil {
+		return true
+	}
+	return catchAccepts(FromASTType(c.errorType, ctx), thrown, ctx)
+}
+
+//catchAccepts checks whether a catch clause for t should handle the thrown error.
+//Unlike normal type checking, error types are matched nominally.
+func catchAccepts(t Type, thrown *Value, ctx *Context) bool {
+	switch t := t.(type) {
+	case *UnionType:
+		return catchAccepts(t.a, thrown, ctx) || catchAccepts(t.b, thrown, ctx)
+	case *StructType:
+		if t.isError() {
+			errorType, isStruct := thrown.Type.(*StructType)
+			return isStruct && errorType.Extends(t)
+		}
+	}
+	return t.Accepts(thrown.Type, ctx)
+}
+
+func (c *CatchCommand) Exec(ctx *Context, thrown *Value) *ReturnedValue {
+	scope := ctx.EnterBlockScope()
+	caught := &Variable{
+		Name:    c.identifier,
+		Mutable: false,
+		Type:    thrown.Type,
+		Value:   thrown,
+	}
+	if c.address != nil {
+		scope.frame = newFrame(c.frameSize, nil, ctx.frame)
+		scope.defineResolved(c.address, caught)
+	} else {
+		scope.DefineVariable(caught)
+	}
+	result := c.body.Exec(scope)
+	scope.Cleanup()
+	return result
 }
 
 type NamespaceCommand struct {
@@ -542,48 +1127,99 @@ func (c *ImportCommand) Exec(ctx *Context) *ReturnedValue {
 }
 
 type StructDefCommand struct {
-	name   string
-	fields []parserlegacy.StructField
+	name     string
+	generics []parserlegacy.GenericContract
+	fields   []parserlegacy.StructField
 }
 
 func (c *StructDefCommand) Exec(ctx *Context) *ReturnedValue {
+	c.structType(ctx, nil, true)
+	return NilValue()
+}
 
-	properties := make([]Property, len(c.fields))
+//structType creates the type declared by c and defines it in ctx.
+//The type is defined before its properties are resolved, so that their types can refer back to it, such as through an alias of Node?.
+//A struct declared inside an extend block derives from the extended struct, starting with all of its properties.
+//The default values of properties are only evaluated if evaluateDefaults is true. Otherwise they are left as a placeholder
+//of the property's type, which is enough to know the parameters of the struct's constructor.
+func (c *StructDefCommand) structType(ctx *Context, parent *StructType, evaluateDefaults bool) *StructType {
+	structType := &StructType{
+		TypeName: c.name,
+		parent:   parent,
+	}
+	ctx.types[c.name] = structType
+	properties := make([]Property, 0, len(c.fields))
 	propertyPositions := map[string]int{}
+	if parent != nil {
+		if len(parent.TypeParameters) != 0 || len(c.generics) != 0 {
+			panic("Struct " + c.name + " cannot derive from " + parent.Name() + " as generic inheritance is not supported")
+		}
+		for _, property := range parent.Properties {
+			if property.Name == "type" && parent.isError() {
+				property.DefaultValue = StringValue(c.name) //Derived errors report their own type
+			}
+			propertyPositions[property.Name] = len(properties)
+			properties = append(properties, property)
+		}
+	}
 
-	for i, field := range c.fields {
+	typeContext := ctx
+	var typeParameters []*TypeParameter
+	if len(c.generics) != 0 {
+		typeParameters = NewTypeParameters(c.generics, ctx)
+		structType.TypeParameters = typeParameters
+		typeContext = ctx.EnterTypeScope(typeParameters)
+	}
+
+	for _, field := range c.fields {
+		if _, exists := propertyPositions[field.Identifier]; exists {
+			if parent != nil && propertyPositions[field.Identifier] < len(parent.Properties) {
+				throw(ctx, TypeErrorType, "Property "+field.Identifier+" of "+c.name+" is already defined by "+parent.Name())
+			}
+			throw(ctx, TypeErrorType, "Property "+field.Identifier+" of "+c.name+" is defined more than once")
+		}
 		var Type Type
 		if field.FieldType == nil {
 			Type = AnyType
 		} else {
-			Type = FromASTType(*field.FieldType, ctx)
+			Type = FromASTType(*field.FieldType, typeContext)
 		}
 
 		var defaultValue *Value
-		if field.Default != nil {
+		if field.Default != nil && evaluateDefaults {
 			defaultValue = ExpressionToCommand(field.Default).Exec(ctx).Unwrap()
+		} else if field.Default != nil {
+			defaultValue = &Value{Type: Type}
 		}
 
 		modifiers := uint(0)
 		if field.Mutable {
 			modifiers |= Mut
 		}
-		properties[i] = Property{
+		propertyPositions[field.Identifier] = len(properties)
+		properties = append(properties, Property{
 			Name:         field.Identifier,
 			Modifiers:    modifiers,
 			Type:         Type,
 			DefaultValue: defaultValue,
-		}
-		propertyPositions[field.Identifier] = i
+		})
 	}
-
-	ctx.types[c.name] = &StructType{
-		TypeName:          c.name,
-		Properties:        properties,
-		propertyPositions: propertyPositions,
+	if typeContext != ctx {
+		typeContext.Cleanup()
 	}
 
-	return NilValue()
+	structType.Properties = properties
+	structType.propertyPositions = propertyPositions
+	return structType
+}
+
+//derive creates the struct declared by c in an extend block of extending, which must be a struct
+func (c *StructDefCommand) derive(ctx *Context, extending Type, evaluateDefaults bool) *StructType {
+	parent, isStruct := extending.(*StructType)
+	if !isStruct {
+		panic("Cannot derive struct " + c.name + " from non struct type " + extending.Name())
+	}
+	return c.structType(ctx, parent, evaluateDefaults)
 }
 
 type ExtendCommand struct {
@@ -618,10 +1254,11 @@ func (c *ExtendCommand) Exec(ctx *Context) *ReturnedValue {
 				for i := range signature.Parameters {
 					p := signature.Parameters[i]
 					p.Position++
-					params[i] = p
+					params[i+1] = p
 				}
 				signature.Parameters = params
 				asFunction.Signature = signature
+				asFunction.isExtension = true
 			}
 
 			variable := &Variable{
@@ -637,6 +1274,8 @@ func (c *ExtendCommand) Exec(ctx *Context) *ReturnedValue {
 			}
 
 			ctx.DefineExtension(extending, statement.Name, extension)
+		case *StructDefCommand:
+			statement.derive(ctx, extending, true)
 		}
 	}
 	return NilValue()
@@ -652,8 +1291,11 @@ func (c *TypeCheckCommand) Exec(ctx *Context) *ReturnedValue {
 	if checkAgainst == nil {
 		panic("No such type " + util.Stringify(c.checkType))
 	}
-	res := c.expression.Exec(ctx).Unwrap()
-	is := checkAgainst.Accepts(res.Type, ctx)
+	returned := c.expression.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	is := checkAgainst.Accepts(returned.Value.Type, ctx)
 	return NonReturningValue(BooleanValue(is))
 }
 
@@ -665,6 +1307,9 @@ type WhileCommand struct {
 func (c *WhileCommand) Exec(ctx *Context) *ReturnedValue {
 	for {
 		val := c.condition.Exec(ctx)
+		if val.IsReturning {
+			return val
+		}
 		condition, ok := val.Unwrap().Value.(bool)
 		if !ok {
 			panic("If statements requires boolean condition")
@@ -672,7 +1317,10 @@ func (c *WhileCommand) Exec(ctx *Context) *ReturnedValue {
 		if !condition {
 			break
 		}
-		c.body.Exec(ctx)
+		returned := c.body.Exec(ctx)
+		if returned.IsReturning {
+			return returned
+		}
 	}
 	return NilValue()
 }
@@ -684,25 +1332,26 @@ type CollectionCommand struct {
 func (c *CollectionCommand) Exec(ctx *Context) *ReturnedValue {
 	elements := make([]*Value, len(c.Elements))
 	for i, element := range c.Elements {
-		elements[i] = element.Exec(ctx).Unwrap()
-	}
-	//In a proper type system we might try and find a union of all elements, but this is dynamic, and I'm lazy
-	var collType Type
-	if len(elements) == 0 {
-		collType = AnyType
-	} else {
-		collType = elements[0].Type
+		returned := element.Exec(ctx)
+		if returned.IsReturning {
+			return returned
+		}
+		elements[i] = returned.Value
 	}
+	return NonReturningValue(collectionOf(elements, ctx))
+}
+
+func collectionOf(elements []*Value, ctx *Context) *Value {
 	collection := &Collection{
-		ElementType: collType,
+		ElementType: leastUpperBoundOf(elements, ctx),
 		Elements:    elements,
 	}
 	collectionType := NewCollectionType(collection)
 
-	return NonReturningValue(&Value{
+	return &Value{
 		Type:  collectionType,
 		Value: collection,
-	})
+	}
 }
 
 type AccessCommand struct {
@@ -711,35 +1360,58 @@ type AccessCommand struct {
 }
 
 func (c *AccessCommand) Exec(ctx *Context) *ReturnedValue {
-	checking := c.checking.Exec(ctx).Unwrap().Value
-	switch accessingType := checking.(type) {
+	checking := c.checking.Exec(ctx)
+	if checking.IsReturning {
+		return checking
+	}
+	returned := c.index.Exec(ctx)
+	if returned.IsReturning {
+		return returned
+	}
+	return NonReturningValue(access(ctx, checking.Value, returned.Value))
+}
+
+//access returns the element of a collection or map at index
+func access(ctx *Context, checking *Value, index *Value) *Value {
+	switch accessingType := checking.Value.(type) {
 	case *Collection:
-		index, isInt := c.index.Exec(ctx).Unwrap().Value.(int64)
+		position, isInt := index.Value.(int64)
 		if !isInt {
 			panic("Index was not an integer")
 		}
-		return NonReturningValue(accessingType.Elements[index])
+		if position < 0 || position >= int64(len(accessingType.Elements)) {
+			throw(ctx, IndexErrorType, "Index "+strconv.FormatInt(position, 10)+" is out of bounds for a collection of length "+strconv.Itoa(len(accessingType.Elements)))
+		}
+		return accessingType.Elements[position]
 
 	case *Map:
-		index := c.index.Exec(ctx).Unwrap()
-		return NonReturningValue(accessingType.Get(ctx, index))
+		return accessingType.Get(ctx, index)
 	}
 	panic("Indexed access not supported for non-collection type")
 }
 
 type TypeCommand struct {
-	name  string
-	value parserlegacy.Type
+	name     string
+	generics []parserlegacy.GenericContract
+	value    parserlegacy.Type
 }
 
+//The aliased type is only resolved when it is first used, so aliases may be recursive
 func (c *TypeCommand) Exec(ctx *Context) *ReturnedValue {
-	runtimeType := FromASTType(c.value, ctx)
+	c.declare(ctx)
+	return NilValue()
+}
+
+func (c *TypeCommand) declare(ctx *Context) {
 	existing := ctx.FindType(c.name)
 	if existing != nil {
 		panic("Type with name " + c.name + " already exists in current scope")
 	}
-	ctx.types[c.name] = runtimeType
-	return NilValue()
+	if len(c.generics) != 0 {
+		ctx.types[c.name] = NewGenericTypeAlias(c.name, NewTypeParameters(c.generics, ctx), c.value, ctx)
+	} else {
+		ctx.types[c.name] = NewTypeAlias(c.name, c.value, ctx)
+	}
 }
 
 type MapCommand struct {
@@ -753,16 +1425,22 @@ type MapEntry struct {
 func (c *MapCommand) Exec(ctx *Context) *ReturnedValue {
 	elements := make([]*Entry, 0)
 	for _, entry := range c.entries {
-		key := entry.key.Exec(ctx).Unwrap()
-		value := entry.value.Exec(ctx).Unwrap()
+		key := entry.key.Exec(ctx)
+		if key.IsReturning {
+			return key
+		}
+		value := entry.value.Exec(ctx)
+		if value.IsReturning {
+			return value
+		}
 		entry := &Entry{
-			Key:   key,
-			Value: value,
+			Key:   key.Value,
+			Value: value.Value,
 		}
 		elements = append(elements, entry)
 	}
 
-	mapValue := MapOf(elements)
+	mapValue := MapOf(ctx, elements)
 	mapType := mapValue.MapType
 	value := NewValue(mapType, mapValue)
 	return NonReturningValue(value)
@@ -790,11 +1468,17 @@ func ToCommand(statement parserlegacy.Stmt) Command {
 			_, isReturn := cmd.(*ReturnCommand)
 			//Small optimisation, it's not worth transforming anything that won't ever be reached
 			if isReturn {
-				return &BlockCommand{lines: commands[:i+1]}
+				commands = commands[:i+1]
+				break
 			}
 		}
-
-		return &BlockCommand{lines: commands}
+		block := &BlockCommand{lines: commands}
+		for _, line := range commands {
+			if block.scope = scopeFor(*line); block.scope != nil {
+				break
+			}
+		}
+		return block
 
 	case parserlegacy.IfElseStmt:
 		condition := ExpressionToCommand(t.Condition)
@@ -819,6 +1503,26 @@ func ToCommand(statement parserlegacy.Stmt) Command {
 		return &ReturnCommand{
 			nil,
 		}
+	case parserlegacy.ThrowStmt:
+		return &ThrowCommand{error: ExpressionToCommand(t.Error)}
+	case parserlegacy.TryStmt:
+		catches := make([]*CatchCommand, len(t.Catches))
+		for i, catch := range t.Catches {
+			catches[i] = &CatchCommand{
+				identifier: catch.Identifier,
+				errorType:  catch.Type,
+				body:       ToCommand(catch.Body),
+			}
+		}
+		var finally Command
+		if t.Finally != nil {
+			finally = ToCommand(t.Finally)
+		}
+		return &TryCommand{
+			body:    ToCommand(t.Body),
+			catches: catches,
+			finally: finally,
+		}
 	case parserlegacy.NamespaceStmt:
 		return &NamespaceCommand{
 			namespace: t.Namespace,
@@ -830,10 +1534,27 @@ func ToCommand(statement parserlegacy.Stmt) Command {
 	case parserlegacy.StructDefStmt:
 		name := t.Identifier
 		return &StructDefCommand{
-			name:   name,
-			fields: t.StructFields,
+			name:     name,
+			generics: t.Generics,
+			fields:   t.StructFields,
 		}
 
+	case parserlegacy.GenerifiedStmt:
+		switch generified := t.Statement.(type) {
+		case parserlegacy.VarDefStmt:
+			function, isFunction := generified.Value.(parserlegacy.FuncDefExpr)
+			if !isFunction {
+				panic("Generic variable " + generified.Identifier + " must be a function")
+			}
+			function.Generics = append(t.Contracts, function.Generics...)
+			generified.Value = function
+			return ToCommand(generified)
+		case parserlegacy.StructDefStmt:
+			generified.Generics = append(t.Contracts, generified.Generics...)
+			return ToCommand(generified)
+		}
+		panic("Could not handle generic " + reflect.TypeOf(t.Statement).Name())
+
 	case parserlegacy.ExtendStmt:
 		commands := make([]Command, len(t.Body.Stmts))
 		for i, stmt := range t.Body.Stmts {
@@ -852,14 +1573,24 @@ func ToCommand(statement parserlegacy.Stmt) Command {
 		}
 	case parserlegacy.TypeStmt:
 		return &TypeCommand{
-			name:  t.Identifier,
-			value: t.Contract,
+			name:     t.Identifier,
+			generics: t.Generics,
+			value:    t.Contract,
 		}
 	}
 
 	panic("Could not handle " + reflect.TypeOf(statement).Name())
 }
 
+//operatorFunctions maps the arithmetic operators to the functions that implement them, for both binary expressions and compound assignments
+var operatorFunctions = map[lexer.TokenType]string{
+	lexer.Add:      "plus",
+	lexer.Subtract: "minus",
+	lexer.Multiply: "times",
+	lexer.Slash:    "divide",
+	lexer.Mod:      "mod",
+}
+
 func ExpressionToCommand(expr parserlegacy.Expr) Command {
 	return NamedExpressionToCommand(expr, nil)
 }
@@ -914,72 +1645,82 @@ func NamedExpressionToCommand(expr parserlegacy.Expr, name *string) Command {
 		rhs := t.Rhs
 		rhsCmd := ExpressionToCommand(rhs)
 
-		switch op {
-		case lexer.Add:
-			return &InvocationCommand{
-				Invoking: &ContextCommand{receiver: lhsCmd, variable: "plus"},
-				args:     []Command{rhsCmd},
-			}
-		case lexer.Subtract:
+		if function, isOperator := operatorFunctions[op]; isOperator {
 			return &InvocationCommand{
-				Invoking: &ContextCommand{receiver: lhsCmd, variable: "minus"},
-				args:     []Command{rhsCmd},
-			}
-		case lexer.Multiply:
-			return &InvocationCommand{
-				Invoking: &ContextCommand{receiver: lhsCmd, variable: "times"},
-				args:     []Command{rhsCmd},
-			}
-		case lexer.Slash:
-			return &InvocationCommand{
-				Invoking: &ContextCommand{receiver: lhsCmd, variable: "divide"},
+				Invoking: &ContextCommand{receiver: lhsCmd, variable: function},
 				args:     []Command{rhsCmd},
 			}
+		}
+		switch op {
 		case lexer.Equals:
 			return &InvocationCommand{Invoking: &ContextCommand{receiver: lhsCmd, variable: "equals"},
 				args: []Command{rhsCmd},
 			}
 		case lexer.NotEquals:
-			return NewAbstractCommand(func(ctx *Context) *ReturnedValue {
-				command := &InvocationCommand{Invoking: &ContextCommand{receiver: lhsCmd, variable: "equals"},
-					args: []Command{rhsCmd},
-				}
-
-				val := command.Exec(ctx).Unwrap()
-
-				asBool, ok := (*val).Value.(bool)
-				if !ok {
-					panic("equals function did not return bool")
-				}
-				return NonReturningValue(BooleanValue(!asBool))
-			})
-
-		case lexer.Mod:
-			return &InvocationCommand{Invoking: &ContextCommand{receiver: lhsCmd, variable: "mod"},
+			return &NotEqualsCommand{equals: &InvocationCommand{Invoking: &ContextCommand{receiver: lhsCmd, variable: "equals"},
 				args: []Command{rhsCmd},
-			}
+			}}
+
+		case lexer.Elvis:
+			return &ElvisCommand{lhs: lhsCmd, rhs: rhsCmd}
+		case lexer.And:
+			return &AndCommand{lhs: lhsCmd, rhs: rhsCmd}
+		case lexer.Or:
+			return &OrCommand{lhs: lhsCmd, rhs: rhsCmd}
+		}
+	case parserlegacy.UnaryExpr:
+		if t.Op == lexer.Not {
+			return &NotCommand{expression: ExpressionToCommand(t.Rhs)}
 		}
 	case parserlegacy.FuncDefExpr:
+		body := unscoped(ToCommand(t.Statement)) //Variables are declared in the scope of the call
+		markTailCalls(body, true)
 		return &FunctionLiteralCommand{
 			name:       name,
+			generics:   t.Generics,
 			parameters: t.Arguments,
 			returnType: t.ReturnType,
-			body:       ToCommand(t.Statement),
+			body:       body,
 		}
 
 	case parserlegacy.ContextExpr:
 		contextCmd := ExpressionToCommand(t.Context)
 		varName := t.Variable.Identifier
 		return &ContextCommand{
-			contextCmd,
-			varName,
-			util.Hash(varName),
+			receiver:       contextCmd,
+			variable:       varName,
+			hashedVariable: util.Hash(varName),
+			nullSafe:       t.NullSafe,
 		}
 
+	case parserlegacy.NotNullExpr:
+		return &NotNullCommand{expression: ExpressionToCommand(t.Expr)}
+
+	case parserlegacy.PropagateExpr:
+		return &PropagateCommand{expression: ExpressionToCommand(t.Expr)}
+
+	case parserlegacy.NullLiteralExpr:
+		return &LiteralCommand{value: NullValue()}
+
 	case parserlegacy.AssignmentExpr:
-		//TODO contexts
 		name := t.Identifier
+		if t.Context != nil && t.Compound {
+			operation := t.Value.(parserlegacy.BinaryExpr)
+			return &PropertyAssignmentCommand{
+				receiver: ExpressionToCommand(t.Context),
+				property: name,
+				value:    ExpressionToCommand(operation.Rhs),
+				operator: operatorFunctions[operation.Op],
+			}
+		}
 		valueCmd := NamedExpressionToCommand(t.Value, &name)
+		if t.Context != nil {
+			return &PropertyAssignmentCommand{
+				receiver: ExpressionToCommand(t.Context),
+				property: name,
+				value:    valueCmd,
+			}
+		}
 		return &AssignmentCommand{
 			Name:  name,
 			value: valueCmd,
@@ -1003,7 +1744,10 @@ func NamedExpressionToCommand(expr parserlegacy.Expr, name *string) Command {
 				elseBranch[i] = ToCommand(stmt)
 			}
 		}
-		elseResult := ExpressionToCommand(t.ElseResult)
+		var elseResult Command
+		if t.ElseResult != nil {
+			elseResult = ExpressionToCommand(t.ElseResult)
+		}
 
 		return &IfElseExpressionCommand{
 			condition:  condition,
@@ -1011,6 +1755,8 @@ func NamedExpressionToCommand(expr parserlegacy.Expr, name *string) Command {
 			ifResult:   ifResult,
 			elseBranch: elseBranch,
 			elseResult: elseResult,
+			ifScope:    scopeFor(ifBranch...),
+			elseScope:  scopeFor(elseBranch...),
 		}
 
 	case parserlegacy.TypeCheckExpr:
@@ -1021,6 +1767,22 @@ func NamedExpressionToCommand(expr parserlegacy.Expr, name *string) Command {
 	case parserlegacy.GroupExpr:
 		return ExpressionToCommand(t.Group)
 
+	case parserlegacy.MatchExpr:
+		arms := make([]matchArm, len(t.Arms))
+		for i, arm := range t.Arms {
+			arms[i] = matchArm{
+				checkType: arm.Type,
+				result:    ExpressionToCommand(arm.Result),
+			}
+			if arm.Value != nil {
+				arms[i].value = ExpressionToCommand(arm.Value)
+			}
+		}
+		return &MatchCommand{
+			subject: ExpressionToCommand(t.Subject),
+			arms:    arms,
+		}
+
 	case parserlegacy.CollectionExpr:
 		elements := make([]Command, len(t.Elements))
 		for i, element := range t.Elements {
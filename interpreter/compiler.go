@@ -0,0 +1,283 @@
+package interpreter
+
+/*
+compiler turns a resolved Command tree into a Chunk of bytecode.
+Every compiled command leaves exactly one value on the stack (nil for statements), just as every Command returns one value.
+Commands without instructions of their own are executed by the tree walker through OpExec.
+The tree that is compiled is left as it is: commands with compiled bodies (such as functions) are executed as copies with
+their bodies replaced, so the same tree can still be run by the tree walker.
+*/
+type compiler struct {
+	chunk *Chunk
+	depth int //The number of values on the stack at the current instruction
+}
+
+//Compile compiles a command that has already been given addresses by the Resolver into bytecode
+func Compile(name string, command Command) *Chunk {
+	c := &compiler{chunk: &Chunk{name: name}}
+	c.compile(command)
+	return c.chunk
+}
+
+func (c *compiler) compile(command Command) {
+	switch command := command.(type) {
+	case *LiteralCommand:
+		c.emit(OpConstant, c.constant(command.value))
+	case *VariableCommand:
+		c.emit(OpLoad, c.command(command))
+	case *BlockCommand:
+		if command.scope != nil {
+			//The lines are compiled into a chunk of their own, which the block runs in its scope
+			body := c.child("block", &BlockCommand{lines: command.lines})
+			c.exec(&BlockCommand{lines: []*Command{&body}, scope: command.scope})
+			return
+		}
+		if len(command.lines) == 0 {
+			c.emit(OpUnit)
+			return
+		}
+		for i, line := range command.lines {
+			if i != 0 {
+				c.emit(OpPop)
+			}
+			c.compile(*line)
+		}
+	case *DefineVarCommand:
+		index := c.command(command)
+		c.emit(OpCheckDefine, index)
+		c.compile(command.value)
+		c.emit(OpDefine, index)
+	case *AssignmentCommand:
+		index := c.command(command)
+		c.emit(OpAssignTarget, index, -1)
+		toReceiverProperty := len(c.chunk.code) - 2
+		c.compile(command.value)
+		c.emit(OpAssign, index)
+		toEnd := c.emitJump(OpJump)
+		c.depth--
+		c.patch(toReceiverProperty)
+		c.exec(command) //Assigning a property of the receiver is left to the tree walker
+		c.patch(toEnd)
+	case *NotEqualsCommand:
+		c.compile(command.equals)
+		c.emit(OpNotEquals)
+	case *InvocationCommand:
+		for _, arg := range command.args {
+			c.compile(arg)
+		}
+		if context, usingReceiver := command.Invoking.(*ContextCommand); usingReceiver {
+			c.compile(context.receiver) //The receiver is evaluated after the arguments, as it is in the tree walker
+			c.emit(OpInvokeOn, c.command(command), len(command.args))
+			return
+		}
+		c.emit(OpInvoke, c.command(command), len(command.args))
+	case *ContextCommand:
+		c.compile(command.receiver)
+		c.emit(OpProperty, c.command(command))
+	case *AndCommand:
+		c.compile(command.lhs)
+		c.emit(OpBoolean, operatorAnd)
+		toFalse := c.emitJump(OpJumpIfFalse, 0)
+		c.compile(command.rhs)
+		c.emit(OpBoolean, operatorAnd)
+		toEnd := c.emitJump(OpJump)
+		c.depth--
+		c.patch(toFalse)
+		c.emit(OpConstant, c.constant(BooleanValue(false)))
+		c.patch(toEnd)
+	case *OrCommand:
+		c.compile(command.lhs)
+		c.emit(OpBoolean, operatorOr)
+		toRhs := c.emitJump(OpJumpIfFalse, 0)
+		c.emit(OpConstant, c.constant(BooleanValue(true)))
+		toEnd := c.emitJump(OpJump)
+		c.depth--
+		c.patch(toRhs)
+		c.compile(command.rhs)
+		c.emit(OpBoolean, operatorOr)
+		c.patch(toEnd)
+	case *NotCommand:
+		c.compile(command.expression)
+		c.emit(OpBoolean, operatorNot)
+		c.emit(OpNot)
+	case *PropagateCommand:
+		c.compile(command.expression)
+		c.emit(OpPropagate)
+	case *CollectionCommand:
+		for _, element := range command.Elements {
+			c.compile(element)
+		}
+		c.emit(OpCollection, len(command.Elements))
+	case *AccessCommand:
+		c.compile(command.checking)
+		c.compile(command.index)
+		c.emit(OpAccess)
+	case *IfElseCommand:
+		c.compile(command.condition)
+		toElse := c.emitJump(OpJumpIfFalse, 0)
+		c.compile(command.ifBranch)
+		toEnd := c.emitJump(OpJump)
+		c.depth--
+		c.patch(toElse)
+		if command.elseBranch != nil {
+			c.compile(command.elseBranch)
+		} else {
+			c.emit(OpNil)
+		}
+		c.patch(toEnd)
+	case *IfElseExpressionCommand:
+		if command.ifScope != nil || command.elseScope != nil {
+			c.exec(command) //Branches that declare variables are left to the tree walker, which runs them in their scopes
+			return
+		}
+		c.compile(command.condition)
+		toElse := c.emitJump(OpJumpIfFalse, 0)
+		c.compileStatements(command.ifBranch)
+		c.compile(command.ifResult)
+		toEnd := c.emitJump(OpJump)
+		c.depth--
+		c.patch(toElse)
+		c.compileStatements(command.elseBranch)
+		if command.elseResult != nil {
+			c.compile(command.elseResult)
+		} else {
+			c.emit(OpNoBranch)
+			c.depth++ //Never reached, but the branches must agree
+		}
+		c.patch(toEnd)
+	case *WhileCommand:
+		start := len(c.chunk.code)
+		c.compile(command.condition)
+		toEnd := c.emitJump(OpJumpIfFalse, 1)
+		c.compile(command.body)
+		c.emit(OpPop)
+		c.emit(OpJump, start)
+		c.patch(toEnd)
+		c.emit(OpNil)
+	case *ReturnCommand:
+		if command.returning == nil {
+			c.emit(OpUnit)
+		} else {
+			c.compile(command.returning)
+		}
+		c.emit(OpReturn)
+		c.depth++ //Never reached, but the value of the return is still counted as the value of the command
+	case *FunctionLiteralCommand:
+		c.exec(c.function(command))
+	case *ExtendCommand:
+		compiled := *command
+		compiled.statements = make([]Command, len(command.statements))
+		for i, statement := range command.statements {
+			compiled.statements[i] = statement
+			define, isDefine := statement.(*DefineVarCommand)
+			if !isDefine {
+				continue
+			}
+			if function, isFunction := define.value.(*FunctionLiteralCommand); isFunction {
+				compiledDefine := *define
+				compiledDefine.value = c.function(function)
+				compiled.statements[i] = &compiledDefine
+			}
+		}
+		c.exec(&compiled)
+	case *TryCommand:
+		compiled := *command
+		compiled.body = c.child("try", command.body)
+		compiled.catches = make([]*CatchCommand, len(command.catches))
+		for i, catch := range command.catches {
+			compiledCatch := *catch
+			compiledCatch.body = c.child("catch "+catch.identifier, catch.body)
+			compiled.catches[i] = &compiledCatch
+		}
+		if command.finally != nil {
+			compiled.finally = c.child("finally", command.finally)
+		}
+		c.exec(&compiled)
+	default:
+		c.exec(command)
+	}
+}
+
+//compileStatements compiles statements whose results are discarded
+func (c *compiler) compileStatements(statements []Command) {
+	for _, statement := range statements {
+		c.compile(statement)
+		c.emit(OpPop)
+	}
+}
+
+//function returns a copy of a function literal whose body is compiled
+func (c *compiler) function(function *FunctionLiteralCommand) *FunctionLiteralCommand {
+	name := "lambda"
+	if function.name != nil {
+		name = *function.name
+	}
+	compiled := *function
+	compiled.body = c.child(name, function.body)
+	return &compiled
+}
+
+//child compiles the body of a function (or another command run in its own scope) into a separate chunk, where returns leave the chunk
+func (c *compiler) child(name string, body Command) Command {
+	chunk := Compile(name, body)
+	c.chunk.children = append(c.chunk.children, chunk)
+	return chunk
+}
+
+//exec executes a command with the tree walker
+func (c *compiler) exec(command Command) {
+	c.emit(OpExec, c.command(command))
+}
+
+func (c *compiler) emit(op Opcode, operands ...int) {
+	c.chunk.code = append(c.chunk.code, byte(op))
+	for _, operand := range operands {
+		if operand < 0 {
+			operand = 0xFFFF //Patched later
+		}
+		if operand > 0xFFFF {
+			panic("Too many values to compile in " + c.chunk.name)
+		}
+		c.chunk.code = append(c.chunk.code, byte(operand>>8), byte(operand))
+	}
+
+	switch op {
+	case OpConstant, OpNil, OpUnit, OpLoad, OpCheckDefine, OpExec:
+		c.depth++
+	case OpPop, OpDefine, OpJumpIfFalse, OpReturn, OpAccess:
+		c.depth--
+	case OpInvoke, OpCollection:
+		c.depth += 1 - operands[len(operands)-1]
+	case OpInvokeOn:
+		c.depth -= operands[1]
+	}
+	if c.depth > c.chunk.maxDepth {
+		c.chunk.maxDepth = c.depth
+	}
+}
+
+//emitJump emits a jump whose address is patched later, returning where its address is
+func (c *compiler) emitJump(op Opcode, operands ...int) int {
+	c.emit(op, append([]int{-1}, operands...)...)
+	return len(c.chunk.code) - 2*(len(operands)+1)
+}
+
+//patch points a jump emitted by emitJump at the next instruction
+func (c *compiler) patch(jump int) {
+	c.setOperand(jump, len(c.chunk.code))
+}
+
+func (c *compiler) setOperand(offset int, operand int) {
+	c.chunk.code[offset] = byte(operand >> 8)
+	c.chunk.code[offset+1] = byte(operand)
+}
+
+func (c *compiler) constant(value *Value) int {
+	c.chunk.constants = append(c.chunk.constants, value)
+	return len(c.chunk.constants) - 1
+}
+
+func (c *compiler) command(command Command) int {
+	c.chunk.commands = append(c.chunk.commands, command)
+	return len(c.chunk.commands) - 1
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"github.com/ElaraLang/elara/util"
 	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
 )
 
 type Context struct {
@@ -19,6 +22,13 @@ type Context struct {
 	types      map[string]Type
 	parent     *Context
 	function   *Function //Will only be nil if this is a Function scope
+	frame      *frame    //The variables that were given slots by the Resolver
+	captured   bool      //A function literal was evaluated in this context, so it must not be cleaned up
+	block      bool      //The scope of a block, whose variables may shadow those outside of it
+
+	calls         *callStack       //The functions being executed by the Interpreter that created this context
+	acceptances   map[[2]Type]bool //The alias checks in progress in the Interpreter (or type checker) that created this context
+	uninitialised map[uint64]bool  //Top level variables that are declared later in the program, but have not been initialised yet
 }
 
 var globalContext = &Context{
@@ -51,23 +61,30 @@ func (c *Context) DefineVariable(value *Variable) {
 	c.DefineVariableWithHash(hash, value)
 }
 
+//FindFunction finds the overload with the given name that most specifically accepts signature
 func (c *Context) FindFunction(hash uint64, signature *Signature) *Function {
 	vars := c.variables[hash]
 	if vars != nil {
-		matching := make([]*Variable, 0)
+		matching := make([]*Function, 0)
 		for _, variable := range vars {
 			asFunction, isFunction := variable.Value.Value.(*Function)
 			if isFunction {
 				if asFunction.Signature.Accepts(signature, c, false) {
-					matching = append(matching, variable)
+					matching = append(matching, asFunction)
 				}
 			}
 		}
-		if len(matching) > 1 {
-			_ = fmt.Errorf("multiple matching functions with name %s and signature %s", matching[0].Name, signature.String())
+		mostSpecific := mostSpecificFunctions(matching, c)
+		if len(mostSpecific) > 1 {
+			candidates := make([]string, len(mostSpecific))
+			for i, function := range mostSpecific {
+				candidates[i] = function.String()
+			}
+			sort.Strings(candidates)
+			panic(fmt.Sprintf("Ambiguous call to %s with arguments %s, candidates are:\n    %s", util.NillableStringify(mostSpecific[0].name, "<anonymous>"), signature.String(), strings.Join(candidates, "\n    ")))
 		}
-		if len(matching) != 0 {
-			return matching[0].Value.Value.(*Function)
+		if len(mostSpecific) != 0 {
+			return mostSpecific[0]
 		}
 	}
 
@@ -134,6 +151,48 @@ func (c *Context) DefineParameter(pos uint, value *Value) {
 	c.parameters[pos] = value
 }
 
+//findReceiver returns the receiver if c is executing an extension function, or nil otherwise
+func (c *Context) findReceiver() *Value {
+	if c.function == nil || !c.function.isExtension {
+		return nil
+	}
+	return c.FindParameter(0)
+}
+
+//findLocalVariable searches only the scopes of the function currently being executed
+func (c *Context) findLocalVariable(hash uint64) *Variable {
+	for scope := c; scope != nil && scope.function == c.function; scope = scope.parent {
+		vars := scope.variables[hash]
+		if vars != nil {
+			return vars[len(vars)-1]
+		}
+	}
+	return nil
+}
+
+//findReceiverMember resolves an unqualified name inside an extension to a property or extension of the receiver
+func (c *Context) findReceiverMember(receiver *Value, name string) *Value {
+	if instance, isInstance := receiver.Value.(*Instance); isInstance {
+		if _, isProperty := instance.Type.GetProperty(name); isProperty {
+			return instance.Values[name]
+		}
+	}
+	extension := c.FindExtension(receiver.Type, name)
+	if extension == nil {
+		return nil
+	}
+	extensionValue := extension.Value.Value
+	function, isFunction := extensionValue.Value.(*Function)
+	if !isFunction || !function.isExtension {
+		return extensionValue
+	}
+	bound := function.bind(receiver)
+	return &Value{
+		Type:  NewFunctionType(bound),
+		Value: bound,
+	}
+}
+
 func (c *Context) FindParameter(pos uint) *Value {
 	par := c.parameters[pos]
 	if par != nil {
@@ -145,7 +204,13 @@ func (c *Context) FindParameter(pos uint) *Value {
 func (c *Context) FindType(name string) Type {
 	t, ok := c.types[name]
 	if ok {
-		return t
+		return unalias(t)
+	}
+	if c.parent != nil {
+		t := c.parent.FindType(name)
+		if t != nil {
+			return t
+		}
 	}
 	for _, contexts := range c.contextPath {
 		for _, context := range contexts {
@@ -167,6 +232,41 @@ func (c *Context) EnterScope(name string, function *Function, paramLength uint)
 	scope.function = function
 	scope.parameters = make([]*Value, paramLength)
 	scope.extensions = c.extensions
+	scope.frame = c.frame
+	scope.calls = c.calls
+	scope.acceptances = c.acceptances
+	return scope
+}
+
+//uninitialise marks a variable that will be defined in this context as declared, but not initialised yet
+func (c *Context) uninitialise(name string) {
+	c.uninitialised[util.Hash(name)] = true
+}
+
+//isUninitialised checks whether a variable has been declared, but has not been initialised yet
+func (c *Context) isUninitialised(hash uint64) bool {
+	for current := c; current != nil; current = current.parent {
+		if current.uninitialised[hash] {
+			return true
+		}
+	}
+	return false
+}
+
+//EnterBlockScope creates a child scope for a block that defines its own variables, such as a catch clause
+func (c *Context) EnterBlockScope() *Context {
+	scope := c.EnterScope(c.name, c.function, 0)
+	scope.parameters = c.parameters
+	scope.block = true
+	return scope
+}
+
+//EnterTypeScope creates a scope in which the given type parameters can be resolved, for resolving generic declarations
+func (c *Context) EnterTypeScope(typeParameters []*TypeParameter) *Context {
+	scope := c.EnterScope(c.name, c.function, 0)
+	for _, parameter := range typeParameters {
+		scope.types[parameter.name] = parameter
+	}
 	return scope
 }
 
@@ -185,32 +285,45 @@ func (c *Context) FindConstructor(name string) *Value {
 	}
 
 	constructorParams := make([]Parameter, 0)
-	i := uint(0)
 	for _, v := range asStruct.Properties {
 		if v.DefaultValue == nil {
 			constructorParams = append(constructorParams, Parameter{
-				Position: i,
+				Position: uint(len(constructorParams)),
 				Name:     v.Name,
 				Type:     v.Type,
 			})
 		}
-		i++
 	}
 
 	constructor := &Function{
 		Signature: Signature{
-			Parameters: constructorParams,
-			ReturnType: t,
+			TypeParameters: asStruct.TypeParameters,
+			Parameters:     constructorParams,
+			ReturnType:     t,
 		},
 		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
-			values := make(map[string]*Value, len(constructorParams))
+			values := make(map[string]*Value, len(asStruct.Properties))
+			for _, property := range asStruct.Properties {
+				if property.DefaultValue != nil {
+					values[property.Name] = property.DefaultValue
+				}
+			}
 			for _, param := range constructorParams {
 				values[param.Name] = ctx.FindParameter(param.Position)
 			}
+			instanceType := asStruct
+			if len(asStruct.TypeParameters) != 0 {
+				//The type parameters have been bound in this scope by Function.Exec
+				typeArguments := make([]Type, len(asStruct.TypeParameters))
+				for i, parameter := range asStruct.TypeParameters {
+					typeArguments[i] = ctx.FindType(parameter.name)
+				}
+				instanceType = asStruct.instantiate(typeArguments)
+			}
 			return NonReturningValue(&Value{
-				Type: t,
+				Type: instanceType,
 				Value: &Instance{
-					Type:   asStruct,
+					Type:   instanceType,
 					Values: values,
 				},
 			})
@@ -257,6 +370,9 @@ func (c *Context) Stringify(value *Value) string {
 	if value == nil {
 		return "<empty value>"
 	}
+	if value.IsNull() {
+		return "null"
+	}
 
 	return util.Stringify(value.Value)
 
@@ -273,12 +389,51 @@ func (c *Context) DefineExtension(receiverType Type, name string, value *Extensi
 	}
 	extensions[name] = value
 	c.extensions[receiverType] = extensions
+	atomic.AddUint64(&declaredExtensions, 1) //The extension may be a better match for calls that have already been cached
+}
+
+//IsOverloaded returns true if the nearest scope defining the name has more than one function with it
+func (c *Context) IsOverloaded(hash uint64) bool {
+	for scope := c; scope != nil; scope = scope.parent {
+		vars := scope.variables[hash]
+		if vars == nil {
+			continue
+		}
+		functions := 0
+		for _, variable := range vars {
+			if _, isFunction := variable.Value.Value.(*Function); isFunction {
+				functions++
+			}
+		}
+		return functions > 1
+	}
+	return false
 }
 
+//FindExtension finds the most specific extension with the given name whose type accepts receiverType
 func (c *Context) FindExtension(receiverType Type, name string) *Extension {
 	extensions, present := c.extensions[receiverType]
-	if !present {
-		extensions = map[string]*Extension{}
+	if present && extensions[name] != nil {
+		return extensions[name] //No extension can be more specific than one on the exact type
+	}
+
+	candidates := make([]Type, 0)
+	for extendedType, extensions := range c.extensions {
+		if extensions[name] != nil && extendedType.Accepts(receiverType, c) {
+			candidates = append(candidates, extendedType)
+		}
+	}
+	mostSpecific := mostSpecificTypes(candidates, c)
+	switch len(mostSpecific) {
+	case 0:
+		return nil
+	case 1:
+		return c.extensions[mostSpecific[0]][name]
+	}
+	names := make([]string, len(mostSpecific))
+	for i, candidate := range mostSpecific {
+		names[i] = candidate.Name()
 	}
-	return extensions[name]
+	sort.Strings(names)
+	panic("Ambiguous extension " + name + " for type " + receiverType.Name() + ", candidates are extensions on " + strings.Join(names, ", "))
 }
@@ -41,11 +41,32 @@ func (c *Context) Clone() *Context {
 	fromPool.parent = parentClone
 	fromPool.function = c.function
 	fromPool.extensions = c.extensions
+	fromPool.frame = c.frame
+	fromPool.uninitialised = c.uninitialised
+	fromPool.calls = c.calls
+	fromPool.acceptances = c.acceptances
 	return fromPool
 }
 
+/*
+capture returns the context that a function literal evaluated in c is closed over.
+The copy shares c's variables, so captured variables are shared by reference, and c is kept alive after its scope has
+exited rather than being cleaned up, as the function may still need the variables that were not given slots by the Resolver.
+*/
+func (c *Context) capture() *Context {
+	c.captured = true
+	return c.Clone()
+}
+
 func (c *Context) Cleanup() {
+	if c.captured {
+		return //Still used by a function literal that was evaluated in it
+	}
 	c.function = nil
+	c.block = false
+	c.uninitialised = nil
+	c.calls = nil
+	c.acceptances = nil
 
 	for s := range c.variables {
 		delete(c.variables, s)
@@ -59,6 +80,7 @@ func (c *Context) Cleanup() {
 	c.types = map[string]Type{}
 	c.extensions = map[Type]map[string]*Extension{}
 	c.parent = nil
+	c.frame = nil
 	contextPool.Put(c)
 }
 
@@ -67,6 +89,7 @@ func NewContext(init bool) *Context {
 	if !init {
 		return c
 	}
+	c.acceptances = map[[2]Type]bool{}
 	c.DefineVariable(&Variable{
 		Name:    "stdout",
 		Mutable: false,
@@ -0,0 +1,139 @@
+package interpreter
+
+import (
+	"runtime"
+)
+
+//The built in error types. Catch clauses match these nominally, so an IOError is never caught as a TypeError
+var ErrorType = newErrorType("Error", nil)
+var TypeErrorType = newErrorType("TypeError", ErrorType)
+var NameErrorType = newErrorType("NameError", ErrorType)
+var NullErrorType = newErrorType("NullError", ErrorType)
+var IndexErrorType = newErrorType("IndexError", ErrorType)
+var IOErrorType = newErrorType("IOError", ErrorType)
+var MatchErrorType = newErrorType("MatchError", ErrorType)
+
+func newErrorType(name string, parent *StructType) *StructType {
+	return &StructType{
+		TypeName: name,
+		Properties: []Property{
+			{Name: "message", Type: StringType},
+			{Name: "type", Type: StringType, DefaultValue: StringValue(name)},
+			{Name: "stackTrace", Type: NewCollectionTypeOf(StringType), DefaultValue: stringCollectionValue(nil)},
+		},
+		propertyPositions: map[string]int{
+			"message":    0,
+			"type":       1,
+			"stackTrace": 2,
+		},
+		parent: parent,
+	}
+}
+
+//Extends returns true if t is other, or t is descended from other
+func (t *StructType) Extends(other *StructType) bool {
+	for current := t; current != nil; current = current.parent {
+		if current == other {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *StructType) isError() bool {
+	return t.Extends(ErrorType)
+}
+
+//Thrown is the panic payload of an Elara error, so that it can be recovered by a try statement
+type Thrown struct {
+	Value *Value
+}
+
+func (t *Thrown) Error() string {
+	instance := t.Value.Value.(*Instance)
+	message := "Uncaught " + instance.Type.Name() + ": " + instance.Values["message"].String()
+	for _, frame := range instance.Values["stackTrace"].Value.(*Collection).Elements {
+		message += "\n    at " + frame.String()
+	}
+	return message
+}
+
+//callStack holds the names of the functions currently being executed by an Interpreter, innermost last.
+//It is shared by every Context the Interpreter creates, and is nil for contexts that never call functions, such as the typer's.
+type callStack struct {
+	frames []string
+}
+
+func (s *callStack) push(name string) {
+	if s != nil {
+		s.frames = append(s.frames, name)
+	}
+}
+
+func (s *callStack) pop() {
+	if s != nil {
+		s.frames = s.frames[:len(s.frames)-1]
+	}
+}
+
+func (s *callStack) trace() *Value {
+	if s == nil {
+		return stringCollectionValue([]string{})
+	}
+	frames := make([]string, len(s.frames))
+	for i, frame := range s.frames {
+		frames[len(s.frames)-1-i] = frame
+	}
+	return stringCollectionValue(frames)
+}
+
+func stringCollectionValue(values []string) *Value {
+	elements := make([]*Value, len(values))
+	for i, s := range values {
+		elements[i] = StringValue(s)
+	}
+	collection := &Collection{
+		ElementType: StringType,
+		Elements:    elements,
+	}
+	return &Value{
+		Type:  NewCollectionType(collection),
+		Value: collection,
+	}
+}
+
+//NewError creates an error of the given type, with the call stack of ctx as its stack trace
+func NewError(ctx *Context, errorType *StructType, message string) *Value {
+	return &Value{
+		Type: errorType,
+		Value: &Instance{
+			Type: errorType,
+			Values: map[string]*Value{
+				"message":    StringValue(message),
+				"type":       StringValue(errorType.Name()),
+				"stackTrace": ctx.calls.trace(),
+			},
+		},
+	}
+}
+
+//throw panics with an Elara error, which can be caught by a try statement
+func throw(ctx *Context, errorType *StructType, message string) {
+	panic(&Thrown{Value: NewError(ctx, errorType, message)})
+}
+
+//toThrown converts a failure recovered from a panic into an Elara error, or returns nil if it is not one.
+//Interpreter failures that are not already Elara errors are wrapped in an Error, but Go runtime errors are bugs in the interpreter, so are not caught.
+func toThrown(ctx *Context, recovered interface{}) *Thrown {
+	switch r := recovered.(type) {
+	case *Thrown:
+		return r
+	case runtime.Error:
+		return nil
+	case error:
+		return &Thrown{Value: NewError(ctx, ErrorType, r.Error())}
+	case string:
+		return &Thrown{Value: NewError(ctx, ErrorType, r)}
+	}
+	return nil
+}
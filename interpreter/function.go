@@ -11,6 +11,32 @@ type Function struct {
 	Body      Command
 	name      *string
 	context   *Context
+	frameSize int //The number of variables the Resolver gave slots in the function's body
+
+	isExtension bool //Extension functions take their receiver as the first parameter
+}
+
+//bind returns a function that calls the extension function f on the given receiver
+func (f *Function) bind(receiver *Value) *Function {
+	parameters := make([]Parameter, len(f.Signature.Parameters)-1)
+	for i, parameter := range f.Signature.Parameters[1:] {
+		parameter.Position = uint(i)
+		parameters[i] = parameter
+	}
+	signature := f.Signature
+	signature.Parameters = parameters
+	return &Function{
+		Signature: signature,
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			arguments := make([]*Value, len(parameters)+1)
+			arguments[0] = receiver
+			for i := range parameters {
+				arguments[i+1] = ctx.FindParameter(uint(i))
+			}
+			return NonReturningValue(f.Exec(ctx, arguments))
+		}),
+		name: f.name,
+	}
 }
 
 func (f *Function) String() string {
@@ -21,7 +47,32 @@ func (f *Function) String() string {
 	return name + f.Signature.String()
 }
 
-func (f *Function) Exec(ctx *Context, parameters []*Value) (val *Value) {
+func (f *Function) Exec(ctx *Context, parameters []*Value) *Value {
+	var checks []returnCheck //The return types of the functions that made the tail calls leading here, innermost last
+	var checked map[returnCheck]bool
+	for {
+		value, returnType := f.run(ctx, parameters)
+		call, isTailCall := value.Value.(*tailCall)
+		if !isTailCall {
+			(returnCheck{function: f, returnType: returnType}).check(value, ctx)
+			for i := len(checks) - 1; i >= 0; i-- {
+				checks[i].check(value, ctx)
+			}
+			return value
+		}
+		if check := (returnCheck{function: f, returnType: returnType}); returnType != AnyType && !checked[check] {
+			if checked == nil {
+				checked = map[returnCheck]bool{}
+			}
+			checked[check] = true //Recursive functions only need checking once
+			checks = append(checks, check)
+		}
+		f, parameters = call.function, call.arguments
+	}
+}
+
+//run runs the body of the function once, returning its value (which may be a tail call) and the return type it must have
+func (f *Function) run(ctx *Context, parameters []*Value) (*Value, Type) {
 	context := ctx
 	if f.context != nil {
 		//The cached context has highest priority for things like variables, but we set the parent to ensure that we can correctly inherit things like imports
@@ -39,12 +90,27 @@ func (f *Function) Exec(ctx *Context, parameters []*Value) (val *Value) {
 		name = *f.name
 	}
 	scope := context.EnterScope(name, f, uint(len(f.Signature.Parameters)))
+	if f.context != nil {
+		scope.frame = newFrame(f.frameSize, scope.parameters, f.context.frame)
+	}
+	ctx.calls.push(name)
+	defer ctx.calls.pop() //Also popped when an error unwinds the call
+
+	signature := &f.Signature
+	if len(signature.TypeParameters) != 0 {
+		bindings := signature.bind(parameters, ctx)
+		for parameter, bound := range bindings {
+			scope.types[parameter.name] = bound
+		}
+		signature = signature.substitute(bindings)
+	}
 
 	for i, paramValue := range parameters {
-		expectedParameter := f.Signature.Parameters[i]
+		expectedParameter := signature.Parameters[i]
+		paramValue = inferParameters(paramValue, expectedParameter.Type, ctx)
 
 		if !expectedParameter.Type.Accepts(paramValue.Type, ctx) {
-			panic(fmt.Sprintf("Expected %s for parameter %s and got %s (%s)", expectedParameter.Type.Name(), expectedParameter.Name, paramValue.String(), paramValue.Type.Name()))
+			throw(ctx, TypeErrorType, fmt.Sprintf("Expected %s for parameter %s and got %s (%s)%s", expectedParameter.Type.Name(), expectedParameter.Name, paramValue.String(), paramValue.Type.Name(), DescribeMismatch(expectedParameter.Type, paramValue.Type, ctx)))
 		}
 		//
 		//if paramValue.Value == nil {
@@ -58,19 +124,15 @@ func (f *Function) Exec(ctx *Context, parameters []*Value) (val *Value) {
 	if value == nil {
 		value = UnitValue()
 	}
-	if !f.Signature.ReturnType.Accepts(value.Type, ctx) {
-		name := "<anonymous>"
-		if f.name != nil {
-			name = *f.name
-		}
-		panic(fmt.Sprintf("Function '%s' did not return value of type %s, instead was %s", name, f.Signature.ReturnType.Name(), value.Type.Name()))
-	}
-	return value
+	return value, signature.ReturnType
 }
 
 type Signature struct {
-	Parameters []Parameter
-	ReturnType Type
+	TypeParameters []*TypeParameter //Only present on generic functions
+	Parameters     []Parameter
+	ReturnType     Type
+
+	returnTypeInferred bool //The return type was not declared, so the ReturnType is Any
 }
 
 func (s *Signature) String() string {
@@ -78,7 +140,11 @@ func (s *Signature) String() string {
 	for i := range s.Parameters {
 		paramNames[i] = s.Parameters[i].Type.Name()
 	}
-	return fmt.Sprintf("(%s) => %s", strings.Join(paramNames, ", "), s.ReturnType.Name())
+	signature := fmt.Sprintf("(%s) => %s", strings.Join(paramNames, ", "), s.ReturnType.Name())
+	if len(s.TypeParameters) != 0 {
+		return typeParametersString(s.TypeParameters) + " " + signature
+	}
+	return signature
 }
 
 func (s *Signature) Accepts(other *Signature, ctx *Context, compareReturnTypes bool) bool {
@@ -87,7 +153,7 @@ func (s *Signature) Accepts(other *Signature, ctx *Context, compareReturnTypes b
 	}
 	for i, parameter := range s.Parameters {
 		otherParam := other.Parameters[i]
-		if !parameter.Type.Accepts(otherParam.Type, ctx) {
+		if !acceptsArgument(parameter.Type, otherParam.Type, ctx) {
 			return false
 		}
 	}
@@ -97,8 +163,172 @@ func (s *Signature) Accepts(other *Signature, ctx *Context, compareReturnTypes b
 	return true
 }
 
+//moreSpecific returns true if every parameter of s is at least as specific as the corresponding parameter of other,
+//and at least one is strictly more specific
+func (s *Signature) moreSpecific(other *Signature, ctx *Context) bool {
+	strictly := false
+	for i, parameter := range s.Parameters {
+		otherParameter := other.Parameters[i]
+		if moreSpecific(parameter.Type, otherParameter.Type, ctx) {
+			strictly = true
+			continue
+		}
+		if !parameter.Type.Accepts(otherParameter.Type, ctx) || !otherParameter.Type.Accepts(parameter.Type, ctx) {
+			return false
+		}
+	}
+	return strictly
+}
+
+//equivalent returns true if s and other accept exactly the same arguments
+func (s *Signature) equivalent(other *Signature, ctx *Context) bool {
+	return s.Accepts(other, ctx, false) && other.Accepts(s, ctx, false)
+}
+
+//mostSpecificFunctions filters overloads to those that no other overload is more specific than
+func mostSpecificFunctions(functions []*Function, ctx *Context) []*Function {
+	if len(functions) < 2 {
+		return functions
+	}
+	mostSpecific := make([]*Function, 0, len(functions))
+	for _, function := range functions {
+		isMostSpecific := true
+		for _, other := range functions {
+			if other != function && other.Signature.moreSpecific(&function.Signature, ctx) {
+				isMostSpecific = false
+				break
+			}
+		}
+		if isMostSpecific {
+			mostSpecific = append(mostSpecific, function)
+		}
+	}
+	return mostSpecific
+}
+
+//argumentSignature creates the signature that a call with the given arguments must be accepted by
+func argumentSignature(arguments []*Value) *Signature {
+	parameters := make([]Parameter, len(arguments))
+	for i, value := range arguments {
+		parameters[i] = Parameter{
+			Name:     fmt.Sprintf("<param%d>", i),
+			Position: uint(i),
+			Type:     value.Type,
+		}
+	}
+	return &Signature{
+		Parameters: parameters,
+		ReturnType: AnyType, //can't infer this rn
+	}
+}
+
 type Parameter struct {
 	Name     string
 	Position uint
 	Type     Type
+
+	untyped bool //The type was not declared, so it is Any unless it can be inferred from where the function is used
+}
+
+/*
+inferParameters gives the undeclared parameters of a function the types of the corresponding parameters of expected,
+so that (x) => x * 2 used as an (Int) => Int takes an Int.
+An undeclared return type is given the return type of expected in the same way, so that the result is checked when the function is called.
+The functions in a collection are inferred from its expected element type, so that [(x) => x] can be used as an [(Int) => Int].
+Any other value, or a function with nothing left to infer, is returned unchanged.
+*/
+func inferParameters(value *Value, expected Type, ctx *Context) *Value {
+	if collection, isCollection := value.Value.(*Collection); isCollection {
+		return inferElements(value, collection, expected, ctx)
+	}
+	function, isFunction := value.Value.(*Function)
+	expectedFunction, expectsFunction := unalias(expected).(*FunctionType)
+	if !isFunction || !expectsFunction || len(function.Signature.Parameters) != len(expectedFunction.Signature.Parameters) {
+		return value
+	}
+	parameters := make([]Parameter, len(function.Signature.Parameters))
+	inferred := false
+	for i, parameter := range function.Signature.Parameters {
+		expectedType := expectedFunction.Signature.Parameters[i].Type
+		if _, isTypeParameter := expectedType.(*TypeParameter); parameter.untyped && !isTypeParameter {
+			parameter.Type = expectedType
+			parameter.untyped = false
+			inferred = true
+		}
+		parameters[i] = parameter
+	}
+	returnType, inferReturn := inferredReturnType(&function.Signature, &expectedFunction.Signature)
+	if !inferred && !inferReturn {
+		return value
+	}
+	inferredFunction := *function
+	inferredFunction.Signature.Parameters = parameters
+	if inferReturn {
+		inferredFunction.Signature.ReturnType = returnType
+		inferredFunction.Signature.returnTypeInferred = false
+	}
+	return &Value{
+		Type:  NewFunctionType(&inferredFunction),
+		Value: &inferredFunction,
+	}
+}
+
+//inferElements infers the functions in a collection expected to hold functions, returning a new collection if any of them changed and all of them are accepted
+func inferElements(value *Value, collection *Collection, expected Type, ctx *Context) *Value {
+	expectedCollection, expectsCollection := unalias(expected).(*CollectionType)
+	if !expectsCollection {
+		return value
+	}
+	if _, expectsFunctions := unalias(expectedCollection.ElementType).(*FunctionType); !expectsFunctions {
+		return value //Only functions have anything to infer, so other collections are not copied
+	}
+	elements := make([]*Value, len(collection.Elements))
+	inferred := false
+	for i, element := range collection.Elements {
+		elements[i] = inferParameters(element, expectedCollection.ElementType, ctx)
+		if !expectedCollection.ElementType.Accepts(elements[i].Type, ctx) {
+			return value
+		}
+		inferred = inferred || elements[i] != element
+	}
+	if !inferred {
+		return value
+	}
+	inferredCollection := &Collection{
+		ElementType: expectedCollection.ElementType,
+		Elements:    elements,
+	}
+	return &Value{
+		Type:  NewCollectionType(inferredCollection),
+		Value: inferredCollection,
+	}
+}
+
+//inferredReturnType returns the return type that a function without a declared return type is given when used as expected, if it is given one.
+//Results that are not used (Unit), or that bind a type parameter, are left as Any.
+func inferredReturnType(signature *Signature, expected *Signature) (Type, bool) {
+	_, returnsTypeParameter := expected.ReturnType.(*TypeParameter)
+	if !signature.returnTypeInferred || expected.ReturnType == UnitType || returnsTypeParameter {
+		return nil, false
+	}
+	return expected.ReturnType, true
+}
+
+/*
+acceptsArgument returns true if a value of type argument can be passed for a parameter of type parameter.
+A function without a declared return type is given the return type of the parameter when it is passed (see inferParameters),
+so it is compared as if it already had it, and its results are checked when it is called.
+*/
+func acceptsArgument(parameter Type, argument Type, ctx *Context) bool {
+	expected, expectsFunction := unalias(parameter).(*FunctionType)
+	function, isFunction := argument.(*FunctionType)
+	if expectsFunction && isFunction {
+		if returnType, isInferred := inferredReturnType(&function.Signature, &expected.Signature); isInferred {
+			signature := function.Signature
+			signature.ReturnType = returnType
+			signature.returnTypeInferred = false
+			argument = NewSignatureFunctionType(signature)
+		}
+	}
+	return parameter.Accepts(argument, ctx)
 }
@@ -0,0 +1,304 @@
+package interpreter
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"strings"
+)
+
+//GenericType is a type that is given type arguments when it is used, such as Box<T> or Result<T, E>
+type GenericType interface {
+	Type
+	Instantiate(typeArguments []Type, ctx *Context) Type
+}
+
+//TypeParameter is a placeholder type (such as T in <T: Contract>) that is bound to a real type per call or instantiation
+type TypeParameter struct {
+	name     string
+	Contract Type
+}
+
+func (t *TypeParameter) Name() string {
+	return t.name
+}
+
+//An unbound type parameter accepts anything that fulfills its contract
+func (t *TypeParameter) Accepts(otherType Type, ctx *Context) bool {
+	if t == otherType {
+		return true
+	}
+	return t.Contract.Accepts(otherType, ctx)
+}
+
+func (t *TypeParameter) String() string {
+	if t.Contract == AnyType {
+		return t.name
+	}
+	return t.name + ": " + t.Contract.Name()
+}
+
+func NewTypeParameter(name string, contract Type) *TypeParameter {
+	return &TypeParameter{
+		name:     name,
+		Contract: contract,
+	}
+}
+
+//NewTypeParameters creates the type parameters for a generic declaration.
+//Contracts are resolved with all of the parameters in scope, so they may refer to each other.
+func NewTypeParameters(contracts []parserlegacy.GenericContract, ctx *Context) []*TypeParameter {
+	typeParameters := make([]*TypeParameter, len(contracts))
+	for i, contract := range contracts {
+		typeParameters[i] = &TypeParameter{
+			name:     contract.Identifier,
+			Contract: AnyType,
+		}
+	}
+	typeScope := ctx.EnterTypeScope(typeParameters)
+	for i, contract := range contracts {
+		if contract.Contract != nil {
+			typeParameters[i].Contract = FromASTType(contract.Contract, typeScope)
+		}
+	}
+	typeScope.Cleanup()
+	return typeParameters
+}
+
+func typeParametersString(typeParameters []*TypeParameter) string {
+	names := make([]string, len(typeParameters))
+	for i, parameter := range typeParameters {
+		names[i] = parameter.String()
+	}
+	return "<" + strings.Join(names, ", ") + ">"
+}
+
+func typeArgumentsString(typeArguments []Type) string {
+	names := make([]string, len(typeArguments))
+	for i, argument := range typeArguments {
+		names[i] = argument.Name()
+	}
+	return "<" + strings.Join(names, ", ") + ">"
+}
+
+//sameTypes checks whether two lists of type arguments are the same types.
+//Instances are found this way rather than by name, as different types (such as the type parameters of different functions) can share a name.
+func sameTypes(a []Type, b []Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameType(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//check throws a TypeError if the argument does not fulfill the contract of the type parameter
+func (t *TypeParameter) check(argument Type, ctx *Context) {
+	if !t.Contract.Accepts(argument, ctx) {
+		throw(ctx, TypeErrorType, "Type "+argument.Name()+" does not fulfill the contract "+t.Contract.Name()+" of type parameter "+t.name)
+	}
+}
+
+//bindTypeParameters infers the type parameters used in declared from the actual type found at runtime.
+//If a type parameter is bound more than once, it is widened so that it accepts both types.
+func bindTypeParameters(declared Type, actual Type, bindings map[*TypeParameter]Type, ctx *Context) {
+	switch declared := declared.(type) {
+	case *TypeParameter:
+		existing, bound := bindings[declared]
+		if !bound || existing.Accepts(actual, ctx) {
+			if !bound {
+				bindings[declared] = actual
+			}
+			return
+		}
+		if actual.Accepts(existing, ctx) {
+			bindings[declared] = actual
+			return
+		}
+		bindings[declared] = &UnionType{a: existing, b: actual}
+
+	case *CollectionType:
+		if actual, ok := actual.(*CollectionType); ok {
+			bindTypeParameters(declared.ElementType, actual.ElementType, bindings, ctx)
+		}
+	case *MapType:
+		if actual, ok := actual.(*MapType); ok {
+			bindTypeParameters(declared.KeyType, actual.KeyType, bindings, ctx)
+			bindTypeParameters(declared.ValueType, actual.ValueType, bindings, ctx)
+		}
+	case *ResultType:
+		if actual, ok := actual.(*ResultType); ok {
+			if actual.ValueType != NothingType {
+				bindTypeParameters(declared.ValueType, actual.ValueType, bindings, ctx)
+			}
+			if actual.ErrorType != NothingType {
+				bindTypeParameters(declared.ErrorType, actual.ErrorType, bindings, ctx)
+			}
+		}
+	case *OptionalType:
+		if actual, ok := actual.(*OptionalType); ok {
+			bindTypeParameters(declared.ElementType, actual.ElementType, bindings, ctx)
+		} else if actual != NullType {
+			bindTypeParameters(declared.ElementType, actual, bindings, ctx)
+		}
+	case *FunctionType:
+		actual, ok := actual.(*FunctionType)
+		if !ok || len(actual.Signature.Parameters) != len(declared.Signature.Parameters) {
+			return
+		}
+		for i, parameter := range declared.Signature.Parameters {
+			bindTypeParameters(parameter.Type, actual.Signature.Parameters[i].Type, bindings, ctx)
+		}
+		bindTypeParameters(declared.Signature.ReturnType, actual.Signature.ReturnType, bindings, ctx)
+	case *StructType:
+		actual, ok := actual.(*StructType)
+		if !ok || declared.generic == nil || declared.generic != actual.generic {
+			return
+		}
+		for i, argument := range declared.TypeArguments {
+			bindTypeParameters(argument, actual.TypeArguments[i], bindings, ctx)
+		}
+	}
+}
+
+//Substitute replaces every bound type parameter in t with the type it is bound to
+func Substitute(t Type, bindings map[*TypeParameter]Type) Type {
+	switch t := t.(type) {
+	case *TypeParameter:
+		bound, isBound := bindings[t]
+		if isBound {
+			return bound
+		}
+		return t
+	case *CollectionType:
+		elementType := Substitute(t.ElementType, bindings)
+		if elementType == t.ElementType {
+			return t
+		}
+		return NewCollectionTypeOf(elementType)
+	case *MapType:
+		keyType := Substitute(t.KeyType, bindings)
+		valueType := Substitute(t.ValueType, bindings)
+		if keyType == t.KeyType && valueType == t.ValueType {
+			return t
+		}
+		return &MapType{KeyType: keyType, ValueType: valueType}
+	case *OptionalType:
+		return NewOptionalType(Substitute(t.ElementType, bindings))
+	case *ResultType:
+		valueType := Substitute(t.ValueType, bindings)
+		errorType := Substitute(t.ErrorType, bindings)
+		if valueType == t.ValueType && errorType == t.ErrorType {
+			return t
+		}
+		return NewResultType(valueType, errorType)
+	case *FunctionType:
+		return NewSignatureFunctionType(*t.Signature.substitute(bindings))
+	case *UnionType:
+		return &UnionType{a: Substitute(t.a, bindings), b: Substitute(t.b, bindings)}
+	case *IntersectionType:
+		return &IntersectionType{a: Substitute(t.a, bindings), b: Substitute(t.b, bindings)}
+	case *StructType:
+		if len(t.TypeParameters) != 0 {
+			//A reference to the generic struct itself, eg a constructor's return type
+			arguments := make([]Type, len(t.TypeParameters))
+			for i, parameter := range t.TypeParameters {
+				arguments[i] = Substitute(parameter, bindings)
+			}
+			return t.instantiate(arguments)
+		}
+		if t.generic != nil {
+			arguments := make([]Type, len(t.TypeArguments))
+			for i, argument := range t.TypeArguments {
+				arguments[i] = Substitute(argument, bindings)
+			}
+			return t.generic.instantiate(arguments)
+		}
+	}
+	return t
+}
+
+//bind infers the type parameters of a generic signature from the arguments it is called with.
+//Type parameters that cannot be inferred are bound to their contract.
+func (s *Signature) bind(arguments []*Value, ctx *Context) map[*TypeParameter]Type {
+	bindings := make(map[*TypeParameter]Type, len(s.TypeParameters))
+	for i, parameter := range s.Parameters {
+		bindTypeParameters(parameter.Type, arguments[i].Type, bindings, ctx)
+	}
+	for _, parameter := range s.TypeParameters {
+		bound, isBound := bindings[parameter]
+		if !isBound {
+			bindings[parameter] = parameter.Contract
+			continue
+		}
+		parameter.check(bound, ctx)
+	}
+	return bindings
+}
+
+//substitute returns a copy of the signature with all of the bound type parameters replaced
+func (s *Signature) substitute(bindings map[*TypeParameter]Type) *Signature {
+	parameters := make([]Parameter, len(s.Parameters))
+	for i, parameter := range s.Parameters {
+		parameter.Type = Substitute(parameter.Type, bindings)
+		parameters[i] = parameter
+	}
+	var typeParameters []*TypeParameter
+	for _, parameter := range s.TypeParameters {
+		if _, isBound := bindings[parameter]; !isBound {
+			typeParameters = append(typeParameters, parameter)
+		}
+	}
+	return &Signature{
+		TypeParameters:     typeParameters,
+		Parameters:         parameters,
+		ReturnType:         Substitute(s.ReturnType, bindings),
+		returnTypeInferred: s.returnTypeInferred,
+	}
+}
+
+//instantiate creates (or reuses) the concrete struct type for a set of type arguments, eg Box<Int> for Box<T>
+//The arguments are assumed to have already been checked against the contracts.
+func (t *StructType) instantiate(typeArguments []Type) *StructType {
+	if len(typeArguments) != len(t.TypeParameters) {
+		panic(fmt.Sprintf("Type %s expects %d type arguments, received %d", t.TypeName, len(t.TypeParameters), len(typeArguments)))
+	}
+	for _, instance := range t.instances {
+		if sameTypes(instance.TypeArguments, typeArguments) {
+			return instance
+		}
+	}
+	bindings := make(map[*TypeParameter]Type, len(typeArguments))
+	for i, parameter := range t.TypeParameters {
+		bindings[parameter] = typeArguments[i]
+	}
+	properties := make([]Property, len(t.Properties))
+	for i, property := range t.Properties {
+		property.Type = Substitute(property.Type, bindings)
+		properties[i] = property
+	}
+	instance := &StructType{
+		TypeName:          t.TypeName,
+		Properties:        properties,
+		propertyPositions: t.propertyPositions,
+		TypeArguments:     typeArguments,
+		generic:           t,
+	}
+	t.instances = append(t.instances, instance)
+	return instance
+}
+
+//Instantiate checks the type arguments against the contracts of t and returns the concrete type
+func (t *StructType) Instantiate(typeArguments []Type, ctx *Context) Type {
+	if len(t.TypeParameters) == 0 {
+		panic("Type " + t.TypeName + " does not take type arguments")
+	}
+	for i, parameter := range t.TypeParameters {
+		if i < len(typeArguments) {
+			parameter.check(typeArguments[i], ctx)
+		}
+	}
+	return t.instantiate(typeArguments)
+}
@@ -0,0 +1,74 @@
+package interpreter
+
+import "sync/atomic"
+
+//receiverCacheSize is the most receiver types an invocation caches extensions for. Call sites that see more are left to the full lookup.
+const receiverCacheSize = 4
+
+//declaredExtensions counts the extensions that have been declared, so that cached lookups made before one can be discarded.
+//It is shared by every interpreter, so is only accessed atomically.
+var declaredExtensions uint64
+
+/*
+receiverCache is a polymorphic inline cache of the extensions that an invocation on a receiver has called, by the type of the receiver.
+Only extensions are cached, as they are found by the type of the receiver alone and can only be changed by declaring another extension.
+Functions taking the receiver as their first parameter are ordinary variables, which may be reassigned, so are always looked up.
+*/
+type receiverCache struct {
+	entries    [receiverCacheSize]receiverCacheEntry
+	size       int
+	extensions uint64 //The value of declaredExtensions when the entries were cached
+}
+
+type receiverCacheEntry struct {
+	receiver  Type
+	extension *Function
+}
+
+//find returns the cached extension for a receiver, or nil if it has not been cached
+func (r *receiverCache) find(receiver Type) *Function {
+	if declared := atomic.LoadUint64(&declaredExtensions); r.extensions != declared {
+		r.size = 0
+		r.extensions = declared
+		return nil
+	}
+	for i := 0; i < r.size; i++ {
+		entry := &r.entries[i]
+		if sameType(entry.receiver, receiver) {
+			return entry.extension
+		}
+	}
+	return nil
+}
+
+//add caches the extension found for a receiver, unless the cache is full
+func (r *receiverCache) add(receiver Type, extension *Function) {
+	if r.size == receiverCacheSize || r.extensions != atomic.LoadUint64(&declaredExtensions) {
+		return
+	}
+	r.entries[r.size] = receiverCacheEntry{receiver: receiver, extension: extension}
+	r.size++
+}
+
+//sameType checks whether a and b are the same type.
+//Collection, map, optional and union types are created wherever they are used, so are compared by their parts.
+func sameType(a Type, b Type) bool {
+	if a == b {
+		return true
+	}
+	switch a := a.(type) {
+	case *CollectionType:
+		b, ok := b.(*CollectionType)
+		return ok && sameType(a.ElementType, b.ElementType)
+	case *MapType:
+		b, ok := b.(*MapType)
+		return ok && sameType(a.KeyType, b.KeyType) && sameType(a.ValueType, b.ValueType)
+	case *OptionalType:
+		b, ok := b.(*OptionalType)
+		return ok && sameType(a.ElementType, b.ElementType)
+	case *UnionType:
+		b, ok := b.(*UnionType)
+		return ok && sameType(a.a, b.a) && sameType(a.b, b.b)
+	}
+	return false
+}
@@ -4,18 +4,41 @@ import (
 	"fmt"
 	"github.com/ElaraLang/elara/parserlegacy"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 type Interpreter struct {
-	lines   []parserlegacy.Stmt
-	context *Context
+	lines    []parserlegacy.Stmt
+	context  *Context
+	resolver *Resolver
+	bytecode bool //Lines are compiled to bytecode and run on the VM rather than by walking their commands
 }
 
-func NewInterpreter(code []parserlegacy.Stmt) *Interpreter {
-	return &Interpreter{
-		lines:   code,
-		context: NewContext(true),
+//Option changes how an Interpreter runs code
+type Option func(*Interpreter)
+
+//WithBytecode makes the Interpreter compile each line to bytecode before running it
+func WithBytecode() Option {
+	return func(s *Interpreter) {
+		s.bytecode = true
+	}
+}
+
+func NewInterpreter(code []parserlegacy.Stmt, options ...Option) *Interpreter {
+	context := NewContext(true)
+	context.frame = newFrame(0, nil, nil)
+	context.uninitialised = map[uint64]bool{}
+	context.calls = &callStack{}
+	interpreter := &Interpreter{
+		lines:    code,
+		context:  context,
+		resolver: NewResolver(),
+	}
+	for _, option := range options {
+		option(interpreter)
 	}
+	return interpreter
 }
 func NewEmptyInterpreter() *Interpreter {
 	return NewInterpreter([]parserlegacy.Stmt{})
@@ -25,18 +48,108 @@ func (s *Interpreter) ResetLines(lines *[]parserlegacy.Stmt) {
 	s.lines = *lines
 }
 
+//command converts a line to its command, giving addresses to the variables it uses
+func (s *Interpreter) command(index int) Command {
+	command := ToCommand(s.lines[index])
+	s.resolver.Resolve(command)
+	return command
+}
+
+//compile compiles the command of a line to bytecode
+func (s *Interpreter) compile(index int, command Command) *Chunk {
+	return Compile("line "+strconv.Itoa(index+1), command)
+}
+
 func (s *Interpreter) Exec(scriptMode bool) []*Value {
 	values := make([]*Value, len(s.lines))
 
-	for i := 0; i < len(s.lines); i++ {
-		line := s.lines[i]
-		command := ToCommand(line)
-		res := command.Exec(s.context).Unwrap()
-		values[i] = res
+	commands := make([]Command, len(s.lines))
+	runnable := make([]Command, len(s.lines))
+	for i := range s.lines {
+		commands[i] = s.command(i)
+		runnable[i] = commands[i]
+		if s.bytecode {
+			runnable[i] = s.compile(i, commands[i])
+		}
+	}
+	declared := s.declare(commands, runnable, values)
+
+	for i, command := range runnable {
+		if !declared[i] {
+			values[i] = command.Exec(s.context).Unwrap()
+		}
 		if scriptMode {
-			formatted := s.context.Stringify(res) + " " + reflect.TypeOf(res).String()
+			formatted := s.context.Stringify(values[i]) + " " + reflect.TypeOf(values[i]).String()
 			fmt.Println(formatted)
 		}
 	}
 	return values
 }
+
+/*
+declare runs the top level declarations before any other line, so that they can be used before the line they are written on,
+such as by functions that call each other. Namespaces and imports run first, then structs and types, and then functions,
+each in the order they were written. Other variables are only marked as uninitialised until their line runs.
+The declarations are found in commands, and run from runnable, which is either the same commands or their compiled chunks.
+It returns which lines have already been run.
+*/
+func (s *Interpreter) declare(commands []Command, runnable []Command, results []*Value) []bool {
+	declared := make([]bool, len(commands))
+	variables := map[string]bool{} //Functions sharing a name with another variable are left in order, so the redefinition is still reported
+	functions := make([]int, 0)
+	for i, command := range commands {
+		define, isDefine := command.(*DefineVarCommand)
+		if !isDefine {
+			continue
+		}
+		if _, isFunction := define.value.(*FunctionLiteralCommand); isFunction {
+			functions = append(functions, i)
+			continue
+		}
+		variables[define.Name] = true
+		s.context.uninitialise(define.Name)
+	}
+
+	run := func(i int) {
+		results[i] = runnable[i].Exec(s.context).Unwrap()
+		declared[i] = true
+	}
+	for _, isDeclaration := range []func(Command) bool{isNamespace, isTypeDeclaration} {
+		for i, command := range commands {
+			if isDeclaration(command) {
+				run(i)
+			}
+		}
+	}
+	for _, i := range functions {
+		if !variables[commands[i].(*DefineVarCommand).Name] {
+			run(i)
+		}
+	}
+	return declared
+}
+
+func isNamespace(command Command) bool {
+	switch command.(type) {
+	case *NamespaceCommand, *ImportCommand:
+		return true
+	}
+	return false
+}
+
+func isTypeDeclaration(command Command) bool {
+	switch command.(type) {
+	case *StructDefCommand, *TypeCommand:
+		return true
+	}
+	return false
+}
+
+//Disassemble compiles every line to bytecode without running them, and returns the listing of their instructions
+func (s *Interpreter) Disassemble() string {
+	listings := make([]string, len(s.lines))
+	for i := range s.lines {
+		listings[i] = s.compile(i, s.command(i)).Disassemble()
+	}
+	return strings.Join(listings, "\n")
+}
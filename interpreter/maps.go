@@ -20,18 +20,26 @@ type Entry struct {
 	Value *Value
 }
 
+//Get returns the value for the given key, or null if the map does not contain the key
 func (m *Map) Get(ctx *Context, key *Value) *Value {
 	for _, element := range m.Elements {
 		if element.Key.Equals(ctx, key) {
 			return element.Value
 		}
 	}
-	return nil
+	return NullValue()
 }
-func MapOf(elements []*Entry) *Map {
+//MapOf creates a map whose key and value types are the least upper bounds of its keys and values
+func MapOf(ctx *Context, elements []*Entry) *Map {
+	keys := make([]*Value, len(elements))
+	values := make([]*Value, len(elements))
+	for i, element := range elements {
+		keys[i] = element.Key
+		values[i] = element.Value
+	}
 	mapType := &MapType{
-		KeyType:   AnyType,
-		ValueType: AnyType, //tfw type inference
+		KeyType:   leastUpperBoundOf(keys, ctx),
+		ValueType: leastUpperBoundOf(values, ctx),
 	}
 	return &Map{
 		MapType:  mapType,
@@ -0,0 +1,39 @@
+package interpreter
+
+//OptionalType is the type of a value that may be null, written T?
+type OptionalType struct {
+	ElementType Type
+}
+
+func NewOptionalType(elementType Type) Type {
+	_, isOptional := elementType.(*OptionalType)
+	if isOptional || elementType == NullType {
+		return elementType
+	}
+	return &OptionalType{ElementType: elementType}
+}
+
+func (t *OptionalType) Name() string {
+	switch t.ElementType.(type) {
+	case *UnionType, *IntersectionType, *FunctionType:
+		return "(" + t.ElementType.Name() + ")?"
+	}
+	return t.ElementType.Name() + "?"
+}
+
+func (t *OptionalType) Accepts(otherType Type, ctx *Context) bool {
+	if otherType == NullType {
+		return true
+	}
+	switch other := otherType.(type) {
+	case *OptionalType:
+		return t.ElementType.Accepts(other.ElementType, ctx)
+	case *UnionType:
+		return t.Accepts(other.a, ctx) && t.Accepts(other.b, ctx)
+	}
+	return t.ElementType.Accepts(otherType, ctx)
+}
+
+func (v *Value) IsNull() bool {
+	return v.Type == NullType
+}
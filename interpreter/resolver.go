@@ -0,0 +1,333 @@
+package interpreter
+
+/*
+Resolver gives variables and parameters an address before they are executed, so that they can be found by index
+rather than by hashing their name and searching every enclosing context.
+Variables declared in a function, block or catch clause are only stored in the slot of their address.
+Top level variables, overloaded functions, and names that cannot be resolved statically (such as imports and
+members of an extension's receiver) are left without an address, and are stored and looked up by name.
+*/
+type Resolver struct {
+	scope *resolverScope
+}
+
+//address is where a variable was resolved to: the number of frames out from the current one, and its index in that frame
+type address struct {
+	depth     int
+	slot      int
+	parameter bool //The slot is a parameter of a function rather than a variable
+}
+
+//resolverScope is one frame, and everything declared in it
+type resolverScope struct {
+	parent     *resolverScope
+	variables  map[string]int  //The slots of the variables declared in the frame, including those the resolver has not reached yet
+	declared   map[string]bool //The variables declared at the point the resolver has reached
+	overloads  map[string]bool //Functions declared more than once, which are found by name so that calls can choose between them
+	parameters map[string]int
+	size       int
+	global     bool //The top level, whose variables are found by name so they can be used before their declaration, imported and redeclared by a REPL
+	function   bool //The body of a function, which runs after the scopes around it have declared their variables
+	boundary   bool //Names outside of this scope are not resolved, as an extension's receiver members may shadow them
+}
+
+func NewResolver() *Resolver {
+	resolver := &Resolver{scope: newResolverScope(nil, false)}
+	resolver.scope.global = true
+	return resolver
+}
+
+func newResolverScope(parent *resolverScope, boundary bool) *resolverScope {
+	return &resolverScope{
+		parent:     parent,
+		variables:  map[string]int{},
+		declared:   map[string]bool{},
+		overloads:  map[string]bool{},
+		parameters: map[string]int{},
+		boundary:   boundary,
+	}
+}
+
+//predeclare gives slots to the variables declared by statements before any of them are resolved,
+//so that functions declared among them can use the variables declared after them
+func (s *resolverScope) predeclare(statements []Command) {
+	if s.global {
+		return
+	}
+	functions := map[string]int{}
+	for _, statement := range statements {
+		define, isDefine := statement.(*DefineVarCommand)
+		if !isDefine {
+			continue
+		}
+		if _, isFunction := define.value.(*FunctionLiteralCommand); isFunction {
+			functions[define.Name]++
+		}
+		s.slot(define.Name)
+	}
+	for name, count := range functions {
+		if count > 1 {
+			s.overloads[name] = true
+		}
+	}
+}
+
+func (s *resolverScope) slot(name string) int {
+	slot, exists := s.variables[name]
+	if !exists {
+		slot = s.size
+		s.variables[name] = slot
+		s.size++
+	}
+	return slot
+}
+
+//declare returns the address of a variable declared in the current frame, or nil if it is found by name
+func (s *resolverScope) declare(name string) *address {
+	if s.global {
+		return nil
+	}
+	s.declared[name] = true
+	if s.overloads[name] {
+		return nil
+	}
+	return &address{slot: s.slot(name)}
+}
+
+/*
+find returns the address of the nearest declaration of name, or nil if it is found by name.
+Like VariableCommand, the parameters of a function take priority over its variables.
+A variable declared later in a scope is only found from the functions declared in it, which cannot run until it has been declared.
+*/
+func (s *resolverScope) find(name string) *address {
+	depth := 0
+	inFunction := false
+	for scope := s; scope != nil; scope = scope.parent {
+		if slot, isParameter := scope.parameters[name]; isParameter {
+			return &address{depth: depth, slot: slot, parameter: true}
+		}
+		if slot, isVariable := scope.variables[name]; isVariable && (scope.declared[name] || inFunction) {
+			if scope.overloads[name] {
+				return nil
+			}
+			return &address{depth: depth, slot: slot}
+		}
+		if scope.boundary {
+			return nil
+		}
+		inFunction = inFunction || scope.function
+		depth++
+	}
+	return nil
+}
+
+//Resolve gives addresses to every variable used in command, declaring the variables it defines at the top level
+func (r *Resolver) Resolve(command Command) {
+	switch c := command.(type) {
+	case *DefineVarCommand:
+		if function, isFunction := c.value.(*FunctionLiteralCommand); isFunction {
+			c.address = r.scope.declare(c.Name) //Declared first so that recursive calls are resolved
+			r.Resolve(function)
+			return
+		}
+		r.Resolve(c.value)
+		c.address = r.scope.declare(c.Name)
+	case *AssignmentCommand:
+		r.Resolve(c.value)
+		if found := r.scope.find(c.Name); found != nil && !found.parameter {
+			c.address = found
+		}
+	case *PropertyAssignmentCommand:
+		r.Resolve(c.receiver)
+		r.Resolve(c.value)
+	case *VariableCommand:
+		c.address = r.scope.find(c.Variable)
+	case *InvocationCommand:
+		r.Resolve(c.Invoking)
+		r.resolveAll(c.args)
+	case *FunctionLiteralCommand:
+		r.resolveFunction(c, nil)
+	case *BlockCommand:
+		lines := make([]Command, len(c.lines))
+		for i, line := range c.lines {
+			lines[i] = *line
+		}
+		r.inScope(c.scope, lines, func() {
+			r.resolveAll(lines)
+		})
+	case *ContextCommand:
+		r.Resolve(c.receiver)
+	case *NotEqualsCommand:
+		r.Resolve(c.equals)
+	case *ElvisCommand:
+		r.Resolve(c.lhs)
+		r.Resolve(c.rhs)
+	case *AndCommand:
+		r.Resolve(c.lhs)
+		r.Resolve(c.rhs)
+	case *OrCommand:
+		r.Resolve(c.lhs)
+		r.Resolve(c.rhs)
+	case *NotCommand:
+		r.Resolve(c.expression)
+	case *NotNullCommand:
+		r.Resolve(c.expression)
+	case *PropagateCommand:
+		r.Resolve(c.expression)
+	case *IfElseCommand:
+		r.Resolve(c.condition)
+		r.Resolve(c.ifBranch)
+		r.Resolve(c.elseBranch)
+	case *IfElseExpressionCommand:
+		r.Resolve(c.condition)
+		r.inScope(c.ifScope, c.ifBranch, func() {
+			r.resolveAll(c.ifBranch)
+			r.Resolve(c.ifResult)
+		})
+		r.inScope(c.elseScope, c.elseBranch, func() {
+			r.resolveAll(c.elseBranch)
+			r.Resolve(c.elseResult)
+		})
+	case *MatchCommand:
+		r.Resolve(c.subject)
+		for _, arm := range c.arms {
+			r.Resolve(arm.value)
+			r.Resolve(arm.result)
+		}
+	case *ReturnCommand:
+		r.Resolve(c.returning)
+	case *ThrowCommand:
+		r.Resolve(c.error)
+	case *TryCommand:
+		r.Resolve(c.body)
+		for _, catch := range c.catches {
+			r.scope = newResolverScope(r.scope, false)
+			catch.address = r.scope.declare(catch.identifier)
+			r.Resolve(catch.body)
+			catch.frameSize = r.scope.size
+			r.scope = r.scope.parent
+		}
+		r.Resolve(c.finally)
+	case *ExtendCommand:
+		for _, statement := range c.statements {
+			define, isDefine := statement.(*DefineVarCommand)
+			if !isDefine {
+				continue
+			}
+			if function, isFunction := define.value.(*FunctionLiteralCommand); isFunction {
+				r.resolveFunction(function, &c.alias)
+			} else {
+				r.Resolve(define.value)
+			}
+		}
+	case *WhileCommand:
+		r.Resolve(c.condition)
+		r.Resolve(c.body)
+	case *TypeCheckCommand:
+		r.Resolve(c.expression)
+	case *CollectionCommand:
+		r.resolveAll(c.Elements)
+	case *AccessCommand:
+		r.Resolve(c.checking)
+		r.Resolve(c.index)
+	case *MapCommand:
+		for _, entry := range c.entries {
+			r.Resolve(entry.key)
+			r.Resolve(entry.value)
+		}
+	}
+}
+
+func (r *Resolver) resolveAll(commands []Command) {
+	for _, command := range commands {
+		r.Resolve(command)
+	}
+}
+
+//inScope resolves the statements of a block in the frame of its scope, or in the current frame if it has none
+func (r *Resolver) inScope(scope *blockScope, statements []Command, resolve func()) {
+	if scope == nil {
+		r.scope.predeclare(statements)
+		resolve()
+		return
+	}
+	r.scope = newResolverScope(r.scope, false)
+	r.scope.predeclare(statements)
+	resolve()
+	scope.frameSize = r.scope.size
+	r.scope = r.scope.parent
+}
+
+/*
+resolveFunction resolves the body of a function literal in a new frame.
+An extension function takes its receiver as its first parameter, and its receiver's members may shadow anything
+outside of it, so names are only resolved to its own parameters and variables.
+*/
+func (r *Resolver) resolveFunction(function *FunctionLiteralCommand, receiver *string) {
+	r.scope = newResolverScope(r.scope, receiver != nil)
+	r.scope.function = true
+	offset := 0
+	if receiver != nil {
+		r.scope.parameters[*receiver] = 0
+		offset = 1
+	}
+	for i, parameter := range function.parameters {
+		r.scope.parameters[parameter.Name] = i + offset
+	}
+	r.Resolve(function.body)
+	function.frameSize = r.scope.size
+	r.scope = r.scope.parent
+}
+
+//frame holds the values of one function call, block, catch clause or program that were given slots by the Resolver
+type frame struct {
+	variables  []*Variable
+	parameters []*Value
+	enclosing  *frame //The frame of the scope this one was declared in
+}
+
+func newFrame(size int, parameters []*Value, enclosing *frame) *frame {
+	return &frame{
+		variables:  make([]*Variable, size),
+		parameters: parameters,
+		enclosing:  enclosing,
+	}
+}
+
+//findResolvedVariable returns the variable at a resolved address, or nil if it has not been defined (yet)
+func (c *Context) findResolvedVariable(a *address) *Variable {
+	f := c.frame
+	for i := 0; i < a.depth && f != nil; i++ {
+		f = f.enclosing
+	}
+	if f == nil || a.parameter || a.slot >= len(f.variables) {
+		return nil
+	}
+	return f.variables[a.slot]
+}
+
+//findResolved returns the value of the variable or parameter at a resolved address, or nil if it has not been defined
+func (c *Context) findResolved(a *address) *Value {
+	if !a.parameter {
+		variable := c.findResolvedVariable(a)
+		if variable == nil {
+			return nil
+		}
+		return variable.Value
+	}
+	f := c.frame
+	for i := 0; i < a.depth && f != nil; i++ {
+		f = f.enclosing
+	}
+	if f == nil || a.slot >= len(f.parameters) {
+		return nil
+	}
+	return f.parameters[a.slot]
+}
+
+//defineResolved stores a variable in the slot it was resolved to in the current frame
+func (c *Context) defineResolved(a *address, variable *Variable) {
+	if c.frame != nil && a.slot < len(c.frame.variables) {
+		c.frame.variables[a.slot] = variable
+	}
+}
@@ -0,0 +1,190 @@
+package interpreter
+
+import "fmt"
+
+//ResultType is the built in Result<T, E>, holding either an Ok value of type T or an Err value of type E
+type ResultType struct {
+	ValueType Type
+	ErrorType Type
+}
+
+//Result on its own accepts any Result
+var AnyResultType = &ResultType{ValueType: AnyType, ErrorType: AnyType}
+
+func NewResultType(valueType Type, errorType Type) *ResultType {
+	return &ResultType{ValueType: valueType, ErrorType: errorType}
+}
+
+func (t *ResultType) Name() string {
+	return "Result<" + t.ValueType.Name() + ", " + t.ErrorType.Name() + ">"
+}
+
+//Ok(3) has the type Result<Int, Nothing>, which is accepted by any Result<Int, E>
+func (t *ResultType) Accepts(otherType Type, ctx *Context) bool {
+	otherResult, isResult := otherType.(*ResultType)
+	if !isResult {
+		return false
+	}
+	return acceptsOrNothing(t.ValueType, otherResult.ValueType, ctx) && acceptsOrNothing(t.ErrorType, otherResult.ErrorType, ctx)
+}
+
+func acceptsOrNothing(t Type, otherType Type, ctx *Context) bool {
+	return otherType == NothingType || t.Accepts(otherType, ctx)
+}
+
+func (t *ResultType) Instantiate(typeArguments []Type, _ *Context) Type {
+	if len(typeArguments) != 2 {
+		panic(fmt.Sprintf("Type Result expects 2 type arguments, received %d", len(typeArguments)))
+	}
+	return NewResultType(typeArguments[0], typeArguments[1])
+}
+
+type Result struct {
+	IsOk  bool
+	Value *Value
+}
+
+func (r *Result) String() string {
+	if r.IsOk {
+		return "Ok(" + r.Value.String() + ")"
+	}
+	return "Err(" + r.Value.String() + ")"
+}
+
+func (r *Result) Equals(ctx *Context, other *Value) bool {
+	otherResult, isResult := other.Value.(*Result)
+	if !isResult {
+		return false
+	}
+	return r.IsOk == otherResult.IsOk && r.Value.Equals(ctx, otherResult.Value)
+}
+
+func OkValue(value *Value) *Value {
+	return &Value{
+		Type:  NewResultType(value.Type, NothingType),
+		Value: &Result{IsOk: true, Value: value},
+	}
+}
+
+func ErrValue(value *Value) *Value {
+	return &Value{
+		Type:  NewResultType(NothingType, value.Type),
+		Value: &Result{IsOk: false, Value: value},
+	}
+}
+
+func InitResults(ctx *Context) {
+	define(ctx, "Ok", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "value",
+					Type: AnyType,
+				},
+			},
+			ReturnType: AnyResultType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			return NonReturningValue(OkValue(ctx.FindParameter(0)))
+		}),
+	})
+
+	define(ctx, "Err", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "error",
+					Type: AnyType,
+				},
+			},
+			ReturnType: AnyResultType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			return NonReturningValue(ErrValue(ctx.FindParameter(0)))
+		}),
+	})
+
+	define(ctx, "map", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "this",
+					Type: AnyResultType,
+				},
+				{
+					Name:     "transform",
+					Type:     AnyType,
+					Position: 1,
+				},
+			},
+			ReturnType: AnyResultType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			this := ctx.FindParameter(0)
+			result := this.Value.(*Result)
+			if !result.IsOk {
+				return NonReturningValue(this)
+			}
+			transform := asFunction(ctx, ctx.FindParameter(1), "map")
+			return NonReturningValue(OkValue(transform.Exec(ctx, []*Value{result.Value})))
+		}),
+	})
+
+	define(ctx, "flatMap", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "this",
+					Type: AnyResultType,
+				},
+				{
+					Name:     "transform",
+					Type:     AnyType,
+					Position: 1,
+				},
+			},
+			ReturnType: AnyResultType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			this := ctx.FindParameter(0)
+			result := this.Value.(*Result)
+			if !result.IsOk {
+				return NonReturningValue(this)
+			}
+			transform := asFunction(ctx, ctx.FindParameter(1), "flatMap")
+			return NonReturningValue(transform.Exec(ctx, []*Value{result.Value}))
+		}),
+	})
+
+	define(ctx, "getOrElse", &Function{
+		Signature: Signature{
+			Parameters: []Parameter{
+				{
+					Name: "this",
+					Type: AnyResultType,
+				},
+				{
+					Name:     "default",
+					Type:     AnyType,
+					Position: 1,
+				},
+			},
+			ReturnType: AnyType,
+		},
+		Body: NewAbstractCommand(func(ctx *Context) *ReturnedValue {
+			result := ctx.FindParameter(0).Value.(*Result)
+			if !result.IsOk {
+				return NonReturningValue(ctx.FindParameter(1))
+			}
+			return NonReturningValue(result.Value)
+		}),
+	})
+}
+
+func asFunction(ctx *Context, value *Value, functionName string) *Function {
+	function, isFunction := value.Value.(*Function)
+	if !isFunction {
+		throw(ctx, TypeErrorType, "Expected a function for "+functionName+", got "+value.Type.Name())
+	}
+	return function
+}
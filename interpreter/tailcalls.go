@@ -0,0 +1,66 @@
+package interpreter
+
+import "fmt"
+
+/*
+tailCall is the value of an invocation in tail position, which is a call to be made once the function it is in has returned.
+Function.Exec makes the call itself rather than nesting it inside the call that made it, so that recursion in tail position
+(including mutual recursion) runs in constant stack space.
+*/
+type tailCall struct {
+	function  *Function
+	arguments []*Value
+}
+
+/*
+markTailCalls marks the invocations in tail position of the body of a function.
+isValue is whether the value of command is the value of the function, such as the last line of its body.
+The value of every return is also the value of the function, unless the return must still be running when the call is made
+(like the body of a try).
+*/
+func markTailCalls(command Command, isValue bool) {
+	switch c := command.(type) {
+	case *InvocationCommand:
+		c.tail = c.tail || isValue
+	case *ReturnCommand:
+		markTailCalls(c.returning, true)
+	case *BlockCommand:
+		for i, line := range c.lines {
+			markTailCalls(*line, isValue && i == len(c.lines)-1)
+		}
+	case *IfElseCommand:
+		markTailCalls(c.ifBranch, isValue)
+		markTailCalls(c.elseBranch, isValue)
+	case *IfElseExpressionCommand:
+		for _, statement := range c.ifBranch {
+			markTailCalls(statement, false)
+		}
+		for _, statement := range c.elseBranch {
+			markTailCalls(statement, false)
+		}
+		markTailCalls(c.ifResult, isValue)
+		markTailCalls(c.elseResult, isValue)
+	case *WhileCommand:
+		markTailCalls(c.body, false)
+	case *MatchCommand:
+		for _, arm := range c.arms {
+			markTailCalls(arm.result, isValue)
+		}
+	}
+}
+
+//returnCheck is the declared return type of a function that made a tail call, which the value of the call must be checked against
+type returnCheck struct {
+	function   *Function
+	returnType Type
+}
+
+func (r returnCheck) check(value *Value, ctx *Context) {
+	if !r.returnType.Accepts(value.Type, ctx) {
+		name := "<anonymous>"
+		if r.function.name != nil {
+			name = *r.function.name
+		}
+		throw(ctx, TypeErrorType, fmt.Sprintf("Function '%s' did not return value of type %s, instead was %s", name, r.returnType.Name(), value.Type.Name()))
+	}
+}
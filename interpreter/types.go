@@ -18,12 +18,28 @@ type StructType struct {
 	Properties        []Property     //This preserves ordering of properties
 	propertyPositions map[string]int //And this guarantees constant lookup still
 	constructor       *Value         //*Function of the constructor
+
+	TypeParameters []*TypeParameter //Only present on generic structs, eg the T in Box<T>
+	TypeArguments  []Type           //Only present on instantiations of generic structs, eg the Int in Box<Int>
+	generic        *StructType      //The generic struct this type was instantiated from
+	instances      []*StructType
+
+	parent *StructType //The struct this type extends, if any
 }
 
 func (t *StructType) Name() string {
+	if len(t.TypeArguments) != 0 {
+		return t.TypeName + typeArgumentsString(t.TypeArguments)
+	}
+	if len(t.TypeParameters) != 0 {
+		return t.TypeName + typeParametersString(t.TypeParameters)
+	}
 	return t.TypeName
 }
 func (t *StructType) Accepts(otherType Type, ctx *Context) bool {
+	if t == otherType {
+		return true //Also stops structs whose properties refer back to them from being compared forever
+	}
 	otherStruct, ok := otherType.(*StructType)
 	if !ok {
 		return false
@@ -47,6 +63,80 @@ func (t *StructType) GetProperty(identifier string) (Property, bool) {
 	return t.Properties[i], true
 }
 
+//moreSpecific returns true if a is a strict subtype of b.
+//Types that accept each other are only ordered if one struct derives from the other.
+func moreSpecific(a Type, b Type, ctx *Context) bool {
+	if !b.Accepts(a, ctx) {
+		return false
+	}
+	if !a.Accepts(b, ctx) {
+		return true
+	}
+	aStruct, aIsStruct := a.(*StructType)
+	bStruct, bIsStruct := b.(*StructType)
+	return aIsStruct && bIsStruct && aStruct != bStruct && aStruct.Extends(bStruct)
+}
+
+//mostSpecificTypes filters types to those that no other type is more specific than
+func mostSpecificTypes(types []Type, ctx *Context) []Type {
+	mostSpecific := make([]Type, 0, len(types))
+	for _, t := range types {
+		isMostSpecific := true
+		for _, other := range types {
+			if other != t && moreSpecific(other, t, ctx) {
+				isMostSpecific = false
+				break
+			}
+		}
+		if isMostSpecific {
+			mostSpecific = append(mostSpecific, t)
+		}
+	}
+	return mostSpecific
+}
+
+/*
+LeastUpperBound finds the most specific type accepting both a and b.
+Structs are joined by their closest common ancestor, null joins into an optional type,
+and otherwise, if neither type accepts the other, the result is a | b
+*/
+func LeastUpperBound(a Type, b Type, ctx *Context) Type {
+	aStruct, aIsStruct := a.(*StructType)
+	bStruct, bIsStruct := b.(*StructType)
+	if aIsStruct && bIsStruct {
+		for ancestor := aStruct; ancestor != nil; ancestor = ancestor.parent {
+			if bStruct.Extends(ancestor) {
+				return ancestor
+			}
+		}
+	}
+	if a == NullType && b != NullType {
+		return NewOptionalType(b)
+	}
+	if b == NullType && a != NullType {
+		return NewOptionalType(a)
+	}
+	if a.Accepts(b, ctx) {
+		return a
+	}
+	if b.Accepts(a, ctx) {
+		return b
+	}
+	return &UnionType{a: a, b: b}
+}
+
+//leastUpperBoundOf joins the types of all values, or returns AnyType if there are none
+func leastUpperBoundOf(values []*Value, ctx *Context) Type {
+	if len(values) == 0 {
+		return AnyType
+	}
+	bound := values[0].Type
+	for _, value := range values[1:] {
+		bound = LeastUpperBound(bound, value.Type, ctx)
+	}
+	return bound
+}
+
 type Property struct {
 	Name string
 	Type Type
@@ -71,15 +161,52 @@ func (t *FunctionType) Name() string {
 
 /*
 Function acceptance is defined by having the same number of parameters,
-with all of A's parameters accepting the corresponding parameters for B
-and A's return type accepting B's return type
+with all of B's parameters accepting the corresponding parameters for A (contravariance)
+and A's return type accepting B's return type (covariance), unless A returns Unit
 */
 func (t *FunctionType) Accepts(otherType Type, ctx *Context) bool {
+	return t.rejection(otherType, ctx) == ""
+}
+
+//rejection explains why t does not accept otherType, or returns an empty string if it does.
+//A function type returning Unit accepts functions returning anything, as the caller does not use their results, so (x) => x * 2 can be passed where only a callback is wanted.
+//A function without a declared return type returns Any, so it is only accepted once it has been given the expected return type (see inferParameters).
+func (t *FunctionType) rejection(otherType Type, ctx *Context) string {
 	otherFunc, ok := otherType.(*FunctionType)
 	if !ok {
-		return false
+		return otherType.Name() + " is not a function"
+	}
+	expected := &t.Signature
+	actual := &otherFunc.Signature
+	if len(expected.Parameters) != len(actual.Parameters) {
+		return fmt.Sprintf("expected a function taking %d parameters, but it takes %d", len(expected.Parameters), len(actual.Parameters))
+	}
+	for i, parameter := range expected.Parameters {
+		actualParameter := actual.Parameters[i]
+		if !actualParameter.Type.Accepts(parameter.Type, ctx) {
+			return fmt.Sprintf("parameter %d has type %s, which cannot accept every %s", i+1, actualParameter.Type.Name(), parameter.Type.Name())
+		}
 	}
-	return t.Signature.Accepts(&otherFunc.Signature, ctx, false)
+	if expected.ReturnType == UnitType {
+		return "" //The result is not used
+	}
+	if !expected.ReturnType.Accepts(actual.ReturnType, ctx) {
+		return fmt.Sprintf("it returns %s, which is not accepted by %s", actual.ReturnType.Name(), expected.ReturnType.Name())
+	}
+	return ""
+}
+
+//DescribeMismatch explains why expected does not accept actual, for error messages
+func DescribeMismatch(expected Type, actual Type, ctx *Context) string {
+	function, isFunction := expected.(*FunctionType)
+	if !isFunction {
+		return ""
+	}
+	reason := function.rejection(actual, ctx)
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
 }
 
 type EmptyType struct {
@@ -110,10 +237,31 @@ type UnionType struct {
 	b Type
 }
 
+func NewUnionType(a Type, b Type) *UnionType {
+	return &UnionType{a: a, b: b}
+}
+
+//Members returns every type that a value of the union may be, flattening any nested unions
+func (t *UnionType) Members() []Type {
+	members := make([]Type, 0, 2)
+	for _, member := range []Type{unalias(t.a), unalias(t.b)} {
+		if union, isUnion := member.(*UnionType); isUnion {
+			members = append(members, union.Members()...)
+		} else {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
 func (t *UnionType) Name() string {
 	return t.a.Name() + " | " + t.b.Name()
 }
 func (t *UnionType) Accepts(otherType Type, ctx *Context) bool {
+	otherUnion, isUnion := otherType.(*UnionType)
+	if isUnion {
+		return t.Accepts(otherUnion.a, ctx) && t.Accepts(otherUnion.b, ctx)
+	}
 	return t.a.Accepts(otherType, ctx) || t.b.Accepts(otherType, ctx)
 }
 
@@ -137,8 +285,10 @@ type DefinedType struct {
 func (t *DefinedType) Name() string {
 	return t.name
 }
+//Accepts checks that other has every part of the defined type, as a property of a struct or another defined type, or as an extension
 func (t *DefinedType) Accepts(other Type, ctx *Context) bool {
 	asStruct, isStruct := other.(*StructType)
+	asDefined, isDefined := other.(*DefinedType)
 	for s, t2 := range t.parts {
 		if isStruct {
 			property, present := asStruct.GetProperty(s)
@@ -146,6 +296,12 @@ func (t *DefinedType) Accepts(other Type, ctx *Context) bool {
 				continue
 			}
 		}
+		if isDefined {
+			part, present := asDefined.parts[s]
+			if present && t2.Accepts(part, ctx) {
+				continue
+			}
+		}
 		extension := ctx.FindExtension(other, s)
 		if extension != nil && t2.Accepts(extension.Value.Type, ctx) {
 			continue
@@ -156,6 +312,30 @@ func (t *DefinedType) Accepts(other Type, ctx *Context) bool {
 	return true
 }
 
+/*
+DeclareType defines the struct or type alias declared by stmt in ctx (or the structs declared in an extend block)
+without running any of the program, so that it can be type checked before it is executed.
+The default values of struct properties are not evaluated.
+*/
+func DeclareType(stmt parserlegacy.Stmt, ctx *Context) {
+	switch command := ToCommand(stmt).(type) {
+	case *StructDefCommand:
+		command.structType(ctx, nil, false)
+	case *TypeCommand:
+		command.declare(ctx)
+	case *ExtendCommand:
+		extending := ctx.FindType(command.Type)
+		if extending == nil {
+			panic("No such type " + command.Type)
+		}
+		for _, statement := range command.statements {
+			if structDef, isStruct := statement.(*StructDefCommand); isStruct {
+				structDef.derive(ctx, extending, false)
+			}
+		}
+	}
+}
+
 func FromASTType(astType parserlegacy.Type, ctx *Context) Type {
 	switch t := astType.(type) {
 	case parserlegacy.ElementaryTypeContract:
@@ -182,6 +362,18 @@ func FromASTType(astType parserlegacy.Type, ctx *Context) Type {
 		}
 		return NewSignatureFunctionType(signature)
 
+	case parserlegacy.GenericTypeContract:
+		found := ctx.FindType(t.Identifier)
+		generic, isGeneric := found.(GenericType)
+		if !isGeneric {
+			panic("No such generic type " + t.Identifier)
+		}
+		typeArguments := make([]Type, len(t.TypeArgs))
+		for i, arg := range t.TypeArgs {
+			typeArguments[i] = FromASTType(arg, ctx)
+		}
+		return generic.Instantiate(typeArguments, ctx)
+
 	case parserlegacy.CollectionTypeContract:
 		elemType := FromASTType(t.ElemType, ctx)
 		return &CollectionType{
@@ -216,6 +408,8 @@ func FromASTType(astType parserlegacy.Type, ctx *Context) Type {
 		return &MapType{
 			KeyType: keyType, ValueType: valueType,
 		}
+	case parserlegacy.OptionalTypeContract:
+		return NewOptionalType(FromASTType(t.Type, ctx))
 	}
 	panic("Cannot handle " + reflect.TypeOf(astType).Name())
 	return nil
@@ -14,6 +14,9 @@ func (v *Value) String() string {
 	if v == nil {
 		return ""
 	}
+	if v.IsNull() {
+		return "null"
+	}
 	return util.Stringify(v.Value)
 }
 
@@ -47,6 +50,12 @@ func UnitValue() *Value {
 	return unitValue
 }
 
+var nullValue = NewValue(NullType, nil)
+
+func NullValue() *Value {
+	return nullValue
+}
+
 var returnedValues = sync.Pool{
 	New: func() interface{} {
 		return &ReturnedValue{
@@ -0,0 +1,129 @@
+package interpreter
+
+//Exec runs the chunk on a new stack, returning the value left on it, or the value it returned
+func (c *Chunk) Exec(ctx *Context) *ReturnedValue {
+	stack := make([]*Value, 0, c.maxDepth)
+	var targets []*Variable //The variables found by OpAssignTarget
+	code := c.code
+	for ip := 0; ip < len(code); {
+		op := Opcode(code[ip])
+		ip++
+		switch op {
+		case OpConstant:
+			stack = append(stack, c.constants[c.operand(ip)])
+			ip += 2
+		case OpNil:
+			stack = append(stack, nil)
+		case OpUnit:
+			stack = append(stack, UnitValue())
+		case OpPop:
+			stack = stack[:len(stack)-1]
+		case OpLoad:
+			stack = append(stack, c.commands[c.operand(ip)].(*VariableCommand).value(ctx))
+			ip += 2
+		case OpCheckDefine:
+			overload := c.commands[c.operand(ip)].(*DefineVarCommand).checkRedefinition(ctx)
+			stack = append(stack, BooleanValue(overload))
+			ip += 2
+		case OpDefine:
+			value := stack[len(stack)-1]
+			overload := stack[len(stack)-2].Value.(bool)
+			c.commands[c.operand(ip)].(*DefineVarCommand).define(ctx, value, overload)
+			stack = append(stack[:len(stack)-2], nil)
+			ip += 2
+		case OpAssignTarget:
+			assignment := c.commands[c.operand(ip)].(*AssignmentCommand)
+			variable := assignment.target(ctx)
+			if variable == nil {
+				ip = c.operand(ip + 2)
+				continue
+			}
+			targets = append(targets, variable)
+			ip += 4
+		case OpAssign:
+			variable := targets[len(targets)-1]
+			targets = targets[:len(targets)-1]
+			c.commands[c.operand(ip)].(*AssignmentCommand).assign(ctx, variable, stack[len(stack)-1])
+			stack[len(stack)-1] = nil
+			ip += 2
+		case OpJump:
+			ip = c.operand(ip)
+		case OpJumpIfFalse:
+			condition := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			value, ok := condition.Value.(bool)
+			if !ok {
+				panic(conditionErrors[c.operand(ip+2)])
+			}
+			if value {
+				ip += 4
+			} else {
+				ip = c.operand(ip)
+			}
+		case OpInvoke, OpInvokeOn:
+			invocation := c.commands[c.operand(ip)].(*InvocationCommand)
+			count := c.operand(ip + 2)
+			ip += 4
+			var receiver *Value
+			if op == OpInvokeOn {
+				receiver = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			argValues := make([]*Value, count)
+			copy(argValues, stack[len(stack)-count:])
+			stack = stack[:len(stack)-count]
+			for _, arg := range argValues {
+				if arg == nil {
+					panic("Value must not be nil")
+				}
+			}
+			if op == OpInvokeOn {
+				stack = append(stack, invocation.invokeOn(ctx, invocation.Invoking.(*ContextCommand), receiver, argValues))
+			} else {
+				stack = append(stack, invocation.invoke(ctx, argValues))
+			}
+		case OpExec:
+			returned := c.commands[c.operand(ip)].Exec(ctx)
+			if returned.IsReturning {
+				return returned
+			}
+			stack = append(stack, returned.Value)
+			ip += 2
+		case OpReturn:
+			return ReturningValue(stack[len(stack)-1])
+		case OpNoBranch:
+			throw(ctx, MatchErrorType, "No branch of the if expression matched")
+		case OpNotEquals:
+			equal, ok := stack[len(stack)-1].Value.(bool)
+			if !ok {
+				panic("equals function did not return bool")
+			}
+			stack[len(stack)-1] = BooleanValue(!equal)
+		case OpProperty:
+			stack[len(stack)-1] = c.commands[c.operand(ip)].(*ContextCommand).property(ctx, stack[len(stack)-1])
+			ip += 2
+		case OpBoolean:
+			booleanOperand(ctx, stack[len(stack)-1], booleanOperators[c.operand(ip)])
+			ip += 2
+		case OpNot:
+			stack[len(stack)-1] = BooleanValue(!stack[len(stack)-1].Value.(bool))
+		case OpPropagate:
+			returned := propagate(ctx, stack[len(stack)-1])
+			if returned.IsReturning {
+				return returned
+			}
+			stack[len(stack)-1] = returned.Value
+		case OpCollection:
+			count := c.operand(ip)
+			elements := make([]*Value, count)
+			copy(elements, stack[len(stack)-count:])
+			stack = append(stack[:len(stack)-count], collectionOf(elements, ctx))
+			ip += 2
+		case OpAccess:
+			index := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1] = access(ctx, stack[len(stack)-1], index)
+		}
+	}
+	return NonReturningValue(stack[len(stack)-1])
+}
@@ -33,6 +33,10 @@ func isNumerical(ch rune) bool {
 	return unicode.IsNumber(ch)
 }
 
+func isIncrementOrDecrement(str []rune) bool {
+	return (str[0] == '+' || str[0] == '-') && str[1] == str[0]
+}
+
 func isOperatorSymbol(ch rune) bool {
 	return ch == '=' || ch == '+' || ch == '-' || ch == '*' || ch == '/' || ch == '%' || ch == '&' || ch == '|' || ch == '^' || ch == '!' || ch == '>' || ch == '<'
 }
@@ -80,6 +80,15 @@ func (s *TokenReader) Read() (tok TokenType, text []rune, line int, col int) {
 		return Colon, []rune{ch}, s.line, s.col
 	}
 
+	if ch == '?' {
+		s.unread()
+		question, t := s.readQuestionMark()
+		defer func() {
+			s.col += len(t)
+		}()
+		return question, t, s.line, s.col
+	}
+
 	if isAngleBracket(ch) {
 		s.unread()
 		bracket, t := s.readAngleBracket()
@@ -190,6 +199,9 @@ func (s *TokenReader) readIdentifier() (tok TokenType, text []rune) {
 			break
 		}
 	}
+	if end-i > 2 && isIncrementOrDecrement(s.runes[end-2:end]) {
+		end -= 2 //x++ is an increment of x, not an identifier named x++
+	}
 	s.cursor = end
 
 	str := s.runes[i:end]
@@ -216,6 +228,12 @@ func (s *TokenReader) readIdentifier() (tok TokenType, text []rune) {
 					return BooleanTrue, str
 				}
 			}
+			if length == 3 && str[1] == 'r' && str[2] == 'y' {
+				return Try, str
+			}
+			if length == 5 && str[1] == 'h' && str[2] == 'r' && str[3] == 'o' && str[4] == 'w' {
+				return Throw, str
+			}
 			return Identifier, str
 		}
 	case 'i':
@@ -265,9 +283,18 @@ func (s *TokenReader) readIdentifier() (tok TokenType, text []rune) {
 	if runeSliceEq(str, []rune("as")) {
 		return As, str
 	}
+	if runeSliceEq(str, []rune("catch")) {
+		return Catch, str
+	}
+	if runeSliceEq(str, []rune("finally")) {
+		return Finally, str
+	}
 	if runeSliceEq(str, []rune("false")) {
 		return BooleanFalse, str
 	}
+	if runeSliceEq(str, []rune("null")) {
+		return Null, str
+	}
 
 	return Identifier, str
 }
@@ -316,6 +343,17 @@ func (s *TokenReader) readSymbol() (tok TokenType, text []rune) {
 
 	return Illegal, []rune{ch}
 }
+func (s *TokenReader) readQuestionMark() (tok TokenType, text []rune) {
+	ch := s.Advance()
+	switch s.peek() {
+	case '.':
+		return SafeDot, []rune{ch, s.Advance()}
+	case ':':
+		return Elvis, []rune{ch, s.Advance()}
+	}
+	return QuestionMark, []rune{ch}
+}
+
 func (s *TokenReader) readAngleBracket() (tok TokenType, text []rune) {
 	ch1 := s.Advance()
 	ch := s.peek()
@@ -361,20 +399,26 @@ func (s *TokenReader) readOperator() (tok TokenType, text []rune) {
 	str := s.runes[start:end]
 	switch str[0] {
 	case '+':
-		return Add, str
+		return compoundOperator(str, Add, AddEqual, Increment)
 	case '-':
-		return Subtract, str
+		return compoundOperator(str, Subtract, SubtractEqual, Decrement)
 	case '*':
-		return Multiply, str
+		return compoundOperator(str, Multiply, MultiplyEqual, Illegal)
 	case '/':
-		return Slash, str
+		return compoundOperator(str, Slash, SlashEqual, Illegal)
 	case '%':
-		return Mod, str
+		return compoundOperator(str, Mod, ModEqual, Illegal)
 	case '^':
 		return Xor, str
 	case '|':
+		if len(str) == 2 && str[1] == '|' {
+			return Or, str
+		}
 		return TypeOr, str
 	case '&':
+		if len(str) == 2 && str[1] == '&' {
+			return And, str
+		}
 		return TypeAnd, str
 
 	case '>':
@@ -408,6 +452,9 @@ func (s *TokenReader) readOperator() (tok TokenType, text []rune) {
 				return Not, str
 			}
 			n := str[1]
+			if l == 2 && n == '!' {
+				return NotNull, str
+			}
 			if l > 2 || n != '=' {
 				panic("Unknown operator " + string(str))
 			}
@@ -429,6 +476,19 @@ func (s *TokenReader) readOperator() (tok TokenType, text []rune) {
 	return Illegal, str
 }
 
+//compoundOperator distinguishes an arithmetic operator from its assignment (+=) and doubled (++) forms
+func compoundOperator(str []rune, operator TokenType, assignment TokenType, doubled TokenType) (tok TokenType, text []rune) {
+	if len(str) == 2 {
+		if str[1] == '=' {
+			return assignment, str
+		}
+		if str[1] == str[0] && doubled != Illegal {
+			return doubled, str
+		}
+	}
+	return operator, str
+}
+
 //This function is called with the assumption that the beginning " has ALREADY been Advance.
 func (s *TokenReader) readString() (tok TokenType, text []rune) {
 	start := s.cursor
@@ -35,6 +35,10 @@ const (
 	Match
 	As
 	Is
+	Throw
+	Try
+	Catch
+	Finally
 
 	//Operators
 	Add
@@ -50,6 +54,16 @@ const (
 	GreaterEqual
 	LesserEqual
 	Not
+	NotNull // !!
+	Elvis   // ?:
+
+	AddEqual      // +=
+	SubtractEqual // -=
+	MultiplyEqual // *=
+	SlashEqual    // /=
+	ModEqual      // %=
+	Increment     // ++
+	Decrement     // --
 
 	TypeOr  // |
 	TypeAnd // &
@@ -58,6 +72,8 @@ const (
 	Equal
 	Arrow
 	Dot
+	SafeDot      // ?.
+	QuestionMark // ?
 
 	//Literals
 	BooleanTrue
@@ -66,6 +82,7 @@ const (
 	Char
 	Int
 	Float
+	Null
 
 	Comma
 	Colon
@@ -107,6 +124,10 @@ var tokenNames = map[TokenType]string{
 	Match:        "Match",
 	As:           "As",
 	Is:           "Is",
+	Throw:        "Throw",
+	Try:          "Try",
+	Catch:        "Catch",
+	Finally:      "Finally",
 	Add:          "Add",
 	Subtract:     "Subtract",
 	Multiply:     "Multiply",
@@ -120,15 +141,29 @@ var tokenNames = map[TokenType]string{
 	GreaterEqual: "GreaterEqual",
 	LesserEqual:  "LesserEqual",
 	Not:          "Not",
+	NotNull:      "NotNull",
+	Elvis:        "Elvis",
+
+	AddEqual:      "AddEqual",
+	SubtractEqual: "SubtractEqual",
+	MultiplyEqual: "MultiplyEqual",
+	SlashEqual:    "SlashEqual",
+	ModEqual:      "ModEqual",
+	Increment:     "Increment",
+	Decrement:     "Decrement",
+
 	Equal:        "Equal",
 	Arrow:        "Arrow",
 	Dot:          "Dot",
+	SafeDot:      "SafeDot",
+	QuestionMark: "QuestionMark",
 	BooleanTrue:  "True",
 	BooleanFalse: "False",
 	String:       "String",
 	Char:         "Char",
 	Int:          "Int",
 	Float:        "Float",
+	Null:         "Null",
 
 	Comma: "Comma",
 	Colon: "Colon",
@@ -148,6 +183,8 @@ var IllegalIdentifierChars = []bool{
 	'{':  true,
 	'}':  true,
 	'"':  true,
+	'?':  true,
+	'!':  true,
 	'>':  true,
 	'<':  true,
 	' ':  true,
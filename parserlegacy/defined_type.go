@@ -1,19 +1,31 @@
 package parserlegacy
 
-import "github.com/ElaraLang/elara/lexer"
+import (
+	"github.com/ElaraLang/elara/lexer"
+	"unicode"
+)
 
 type DefinedType struct {
 	Identifier string
 	DefType    Type
 }
 
+//definedTypes parses the properties of a defined type contract, each written as either `Type name` or `name: Type`
 func (p *Parser) definedTypes() (types []DefinedType) {
 	types = make([]DefinedType, 0)
 	p.consume(lexer.LBrace, "Expected '{' where defined type starts")
 	p.cleanNewLines()
 	for !p.check(lexer.RBrace) {
-		typ := p.primaryContract(true)
-		id := p.consume(lexer.Identifier, "Expected identifier for type in defined type contract")
+		var typ Type
+		var id Token
+		if p.namedPropertyAt(p.current) {
+			id = p.advance()
+			p.advance() //The colon
+			typ = p.typeContractDefinable()
+		} else {
+			typ = p.primaryContract(true)
+			id = p.consume(lexer.Identifier, "Expected identifier for type in defined type contract")
+		}
 		dTyp := DefinedType{
 			Identifier: string(id.Text),
 			DefType:    typ,
@@ -28,3 +40,18 @@ func (p *Parser) definedTypes() (types []DefinedType) {
 	p.consume(lexer.RBrace, "Expected '}' where defined type ends")
 	return types
 }
+
+//namedPropertyAt checks whether the tokens at index start a property written as `name: Type`
+func (p *Parser) namedPropertyAt(index int) bool {
+	return index+1 < len(p.tokens) && p.tokens[index].TokenType == lexer.Identifier && p.tokens[index+1].TokenType == lexer.Colon
+}
+
+//isNamedPropertyContract checks whether the '{' at the current token starts a defined type contract whose properties are written as `name: Type`.
+//Property names start with a lower case letter, which tells them apart from the key type of a map contract such as {String : Int}.
+func (p *Parser) isNamedPropertyContract() bool {
+	index := p.current + 1
+	for index < len(p.tokens) && p.tokens[index].TokenType == lexer.NEWLINE {
+		index++
+	}
+	return p.namedPropertyAt(index) && unicode.IsLower(p.tokens[index].Text[0])
+}
@@ -31,16 +31,21 @@ type AssignmentExpr struct {
 	Context    Expr
 	Identifier string
 	Value      Expr
+	Compound   bool //Value is a BinaryExpr applying an operator to the target, eg a.b += 1, so the target's receiver should only be evaluated once
+	Position   lexer.Position
 }
 
 type InvocationExpr struct {
-	Invoker Expr
-	Args    []Expr
+	Invoker  Expr
+	Args     []Expr
+	Position lexer.Position
 }
 
 type ContextExpr struct {
 	Context  Expr
 	Variable VariableExpr
+	NullSafe bool //Accessed with ?. rather than .
+	Position lexer.Position
 }
 
 type TypeCastExpr struct {
@@ -58,13 +63,39 @@ type IfElseExpr struct {
 	IfBranch   []Stmt
 	IfResult   Expr
 	ElseBranch []Stmt
-	ElseResult Expr
+	ElseResult Expr //nil if there is no else branch, so every case must be checked by the conditions
+	Position   lexer.Position
+}
+
+//MatchExpr evaluates the result of the first arm that matches the value of Subject
+type MatchExpr struct {
+	Subject  Expr
+	Arms     []MatchArm
+	Position lexer.Position
+}
+
+//MatchArm matches values of Type, values equal to Value, or any value if both are nil (an else arm)
+type MatchArm struct {
+	Type     Type
+	Value    Expr
+	Result   Expr
+	Position lexer.Position
 }
 
 type FuncDefExpr struct {
+	Generics   []GenericContract
 	Arguments  []FunctionArgument
 	ReturnType Type
 	Statement  Stmt
+	Position   lexer.Position
+}
+
+type NotNullExpr struct {
+	Expr Expr
+}
+
+type PropagateExpr struct {
+	Expr Expr
 }
 
 type AccessExpr struct {
@@ -104,6 +135,8 @@ type BooleanLiteralExpr struct {
 	Value bool
 }
 
+type NullLiteralExpr struct{}
+
 func (FuncDefExpr) exprNode()        {}
 func (AccessExpr) exprNode()         {}
 func (CollectionExpr) exprNode()     {}
@@ -113,16 +146,20 @@ func (CharLiteralExpr) exprNode()    {}
 func (IntegerLiteralExpr) exprNode() {}
 func (FloatLiteralExpr) exprNode()   {}
 func (BooleanLiteralExpr) exprNode() {}
+func (NullLiteralExpr) exprNode()    {}
 func (UnaryExpr) exprNode()          {}
 func (BinaryExpr) exprNode()         {}
 func (GroupExpr) exprNode()          {}
 func (ContextExpr) exprNode()        {}
 func (IfElseExpr) exprNode()         {}
+func (MatchExpr) exprNode()          {}
 func (InvocationExpr) exprNode()     {}
 func (AssignmentExpr) exprNode()     {}
 func (VariableExpr) exprNode()       {}
 func (TypeCastExpr) exprNode()       {}
 func (TypeCheckExpr) exprNode()      {}
+func (NotNullExpr) exprNode()        {}
+func (PropagateExpr) exprNode()      {}
 
 func (p *Parser) expression() Expr {
 	if p.peek().TokenType == lexer.If {
@@ -131,39 +168,64 @@ func (p *Parser) expression() Expr {
 	return p.assignment()
 }
 
+//compoundOperators maps compound assignments to the operator they apply, eg x += 1 is x = x + 1
+var compoundOperators = map[TokenType]TokenType{
+	lexer.AddEqual:      lexer.Add,
+	lexer.SubtractEqual: lexer.Subtract,
+	lexer.MultiplyEqual: lexer.Multiply,
+	lexer.SlashEqual:    lexer.Slash,
+	lexer.ModEqual:      lexer.Mod,
+	lexer.Increment:     lexer.Add,
+	lexer.Decrement:     lexer.Subtract,
+}
+
 func (p *Parser) assignment() (expr Expr) {
 	expr = p.typeCast()
 
 	if p.check(lexer.Equal) {
 		eqlTok := p.advance()
 		rhs := p.typeCast()
+		return p.assignTo(expr, rhs, eqlTok, false)
+	}
+	if p.match(lexer.AddEqual, lexer.SubtractEqual, lexer.MultiplyEqual, lexer.SlashEqual, lexer.ModEqual) {
+		opTok := p.previous()
+		rhs := p.typeCast()
+		return p.assignTo(expr, BinaryExpr{Lhs: expr, Op: compoundOperators[opTok.TokenType], Rhs: rhs}, opTok, true)
+	}
+	if p.match(lexer.Increment, lexer.Decrement) {
+		opTok := p.previous()
+		return p.assignTo(expr, BinaryExpr{Lhs: expr, Op: compoundOperators[opTok.TokenType], Rhs: IntegerLiteralExpr{Value: 1}}, opTok, true)
+	}
+	return
+}
 
-		switch v := expr.(type) {
-		case VariableExpr:
-			expr = AssignmentExpr{
-				Identifier: v.Identifier,
-				Value:      rhs,
-			}
-			break
-		case ContextExpr:
-			expr = AssignmentExpr{
-				Context:    v.Context,
-				Identifier: v.Variable.Identifier,
-				Value:      rhs,
-			}
-			break
-		default:
-			panic(ParseError{
-				token:   eqlTok,
-				message: "Invalid type found behind assignment",
-			})
+func (p *Parser) assignTo(target Expr, value Expr, tok Token, compound bool) Expr {
+	switch v := target.(type) {
+	case VariableExpr:
+		return AssignmentExpr{
+			Identifier: v.Identifier,
+			Value:      value,
+			Compound:   compound,
+			Position:   tok.Position,
 		}
+	case ContextExpr:
+		return AssignmentExpr{
+			Context:    v.Context,
+			Identifier: v.Variable.Identifier,
+			Value:      value,
+			Compound:   compound,
+			Position:   tok.Position,
+		}
+	default:
+		panic(ParseError{
+			token:   tok,
+			message: "Invalid type found behind assignment",
+		})
 	}
-	return
 }
 
 func (p *Parser) typeCast() Expr {
-	expr := p.typeCheck()
+	expr := p.logicalOr()
 	for p.match(lexer.As) {
 		expr = TypeCastExpr{
 			Expr: expr,
@@ -173,14 +235,26 @@ func (p *Parser) typeCast() Expr {
 	return expr
 }
 
+//typeCheck parses x is T, and x !is T as !(x is T)
 func (p *Parser) typeCheck() Expr {
-	expr := p.logicalOr()
+	expr := p.comparison()
+	checkIndex := p.current + 1
+	negated := p.check(lexer.Not) && len(p.tokens) > checkIndex && p.tokens[checkIndex].TokenType == lexer.Is
+	if negated {
+		p.advance()
+	}
 	if p.match(lexer.Is) {
 		expr = TypeCheckExpr{
 			Expr: expr,
 			Type: p.typeContractDefinable(),
 		}
 	}
+	if negated {
+		expr = UnaryExpr{
+			Op:  lexer.Not,
+			Rhs: expr,
+		}
+	}
 	return expr
 }
 
@@ -216,11 +290,11 @@ func (p *Parser) logicalAnd() Expr {
 }
 
 func (p *Parser) referenceEquality() (expr Expr) {
-	expr = p.comparison()
+	expr = p.typeCheck()
 
 	for p.match(lexer.Equals, lexer.NotEquals) {
 		op := p.previous()
-		rhs := p.comparison()
+		rhs := p.typeCheck()
 
 		expr = BinaryExpr{
 			Lhs: expr,
@@ -232,11 +306,11 @@ func (p *Parser) referenceEquality() (expr Expr) {
 }
 
 func (p *Parser) comparison() (expr Expr) {
-	expr = p.addition()
+	expr = p.elvis()
 
 	for p.match(lexer.GreaterEqual, lexer.RAngle, lexer.LesserEqual, lexer.LAngle) {
 		op := p.previous()
-		rhs := p.addition()
+		rhs := p.elvis()
 
 		expr = BinaryExpr{
 			Lhs: expr,
@@ -247,6 +321,21 @@ func (p *Parser) comparison() (expr Expr) {
 	return
 }
 
+func (p *Parser) elvis() (expr Expr) {
+	expr = p.addition()
+
+	for p.match(lexer.Elvis) {
+		op := p.previous()
+		rhs := p.addition()
+		expr = BinaryExpr{
+			Lhs: expr,
+			Op:  op.TokenType,
+			Rhs: rhs,
+		}
+	}
+	return
+}
+
 func (p *Parser) addition() (expr Expr) {
 	expr = p.multiplication()
 
@@ -294,23 +383,38 @@ func (p *Parser) unary() (expr Expr) {
 func (p *Parser) invoke() (expr Expr) {
 	expr = p.funDef()
 
-	for p.match(lexer.LParen, lexer.Dot, lexer.LSquare) {
+	for p.match(lexer.LParen, lexer.Dot, lexer.SafeDot, lexer.LSquare, lexer.NotNull, lexer.QuestionMark) {
 		switch p.previous().TokenType {
 		case lexer.LParen:
+			position := p.previous().Position
 			separator := lexer.Comma
 			args := p.invocationParameters(&separator)
 
 			expr = InvocationExpr{
-				Invoker: expr,
-				Args:    args,
+				Invoker:  expr,
+				Args:     args,
+				Position: position,
+			}
+		case lexer.Dot, lexer.SafeDot:
+			position := p.previous().Position
+			nullSafe := p.previous().TokenType == lexer.SafeDot
+			var id Token
+			if p.check(lexer.Type) {
+				id = p.advance() //Allows properties such as error.type
+			} else {
+				id = p.consumeValidIdentifier("Expected identifier inside context getter/setter")
 			}
-		case lexer.Dot:
-			id := p.consumeValidIdentifier("Expected identifier inside context getter/setter")
 
 			expr = ContextExpr{
 				Context:  expr,
 				Variable: VariableExpr{Identifier: string(id.Text)},
+				NullSafe: nullSafe,
+				Position: position,
 			}
+		case lexer.NotNull:
+			expr = NotNullExpr{Expr: expr}
+		case lexer.QuestionMark:
+			expr = PropagateExpr{Expr: expr}
 		case lexer.LSquare:
 			expr = AccessExpr{
 				Expr:  expr,
@@ -337,6 +441,7 @@ func (p *Parser) funDef() Expr {
 			Arguments:  args,
 			ReturnType: typ,
 			Statement:  p.statement(),
+			Position:   tok.Position,
 		}
 	case lexer.LBrace:
 		mapExpr := p.tryParseMapLiteral()
@@ -353,6 +458,7 @@ func (p *Parser) funDef() Expr {
 			Arguments:  make([]FunctionArgument, 0),
 			ReturnType: nil,
 			Statement:  p.blockStatement(),
+			Position:   tok.Position,
 		}
 	case lexer.Arrow:
 		p.advance()
@@ -360,6 +466,7 @@ func (p *Parser) funDef() Expr {
 			Arguments:  make([]FunctionArgument, 0),
 			ReturnType: nil,
 			Statement:  p.exprStatement(),
+			Position:   tok.Position,
 		}
 	default:
 		return p.collection()
@@ -461,6 +568,10 @@ func (p *Parser) primary() (expr Expr) {
 		p.consume(lexer.BooleanFalse, "Expected BooleanFalse")
 		expr = BooleanLiteralExpr{Value: false}
 		break
+	case lexer.Null:
+		p.consume(lexer.Null, "Expected null")
+		expr = NullLiteralExpr{}
+		break
 	case lexer.Int:
 		str := p.consume(lexer.Int, "Expected integer")
 		var integer int64
@@ -480,6 +591,8 @@ func (p *Parser) primary() (expr Expr) {
 
 	case lexer.If:
 		return p.ifElseExpression()
+	case lexer.Match:
+		return p.matchExpression()
 	case lexer.LParen:
 		p.advance()
 		expr = GroupExpr{Group: p.expression()}
@@ -503,7 +616,7 @@ func (p *Parser) primary() (expr Expr) {
 }
 
 func (p *Parser) ifElseExpression() Expr {
-	p.consume(lexer.If, "Expected if at beginning of if expression")
+	tok := p.consume(lexer.If, "Expected if at beginning of if expression")
 	condition := p.logicalOr()
 	if p.peek().TokenType == lexer.Arrow {
 		p.consume(lexer.Arrow, "")
@@ -517,6 +630,7 @@ func (p *Parser) ifElseExpression() Expr {
 			IfResult:   mainResult,
 			ElseBranch: elseBranch,
 			ElseResult: elseResult,
+			Position:   tok.Position,
 		}
 	}
 
@@ -535,12 +649,18 @@ func (p *Parser) ifElseExpression() Expr {
 		IfResult:   mainResult.(ExpressionStmt).Expr,
 		ElseBranch: elseBranch,
 		ElseResult: elseResult,
+		Position:   tok.Position,
 	}
 }
 
+//elseExpression parses the else branch of an if expression, returning a nil result if there is none
 func (p *Parser) elseExpression() ([]Stmt, Expr) {
+	beforeNewLines := p.current
 	p.cleanNewLines()
-	p.consume(lexer.Else, "if expression must follow with else expression")
+	if !p.match(lexer.Else) {
+		p.current = beforeNewLines
+		return nil, nil
+	}
 	if p.peek().TokenType == lexer.Arrow {
 		p.advance()
 		return nil, p.expression()
@@ -558,3 +678,41 @@ func (p *Parser) elseExpression() ([]Stmt, Expr) {
 		return elseBranch.Stmts[:len(elseBranch.Stmts)-1], elseResult.(ExpressionStmt).Expr
 	}
 }
+
+/*
+matchExpression parses a match on a value, such as
+	match animal {
+		Dog => "Woof"
+		null => "Nothing"
+		else => "Something"
+	}
+Arms starting with a literal match values equal to it, and any other arm matches values of its type.
+*/
+func (p *Parser) matchExpression() Expr {
+	tok := p.consume(lexer.Match, "Expected match at beginning of match expression")
+	subject := p.logicalOr()
+	p.consume(lexer.LBrace, "Expected { after value to match")
+	p.cleanNewLines()
+
+	arms := make([]MatchArm, 0)
+	for !p.match(lexer.RBrace) {
+		arm := MatchArm{Position: p.peek().Position}
+		switch p.peek().TokenType {
+		case lexer.Else:
+			p.advance()
+		case lexer.String, lexer.Char, lexer.Int, lexer.Float, lexer.BooleanTrue, lexer.BooleanFalse, lexer.Null:
+			arm.Value = p.primary()
+		default:
+			arm.Type = p.typeContract()
+		}
+		p.consume(lexer.Arrow, "Expected => after match arm")
+		arm.Result = p.expression()
+		arms = append(arms, arm)
+		p.cleanNewLines()
+	}
+	return MatchExpr{
+		Subject:  subject,
+		Arms:     arms,
+		Position: tok.Position,
+	}
+}
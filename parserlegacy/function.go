@@ -46,8 +46,8 @@ func (p *Parser) functionArguments() (args []FunctionArgument) {
 func (p *Parser) functionArgument() FunctionArgument {
 	lazy := p.parseProperties(lexer.Lazy)[0]
 	checkIndex := p.current + 1
-	var typ Type
-	if len(p.tokens) > checkIndex && p.tokens[checkIndex].TokenType != lexer.Equal {
+	var typ Type //nil if the type is not declared, eg the x in (x) => x * 2
+	if len(p.tokens) > checkIndex && !p.isUntypedArgument(p.tokens[checkIndex].TokenType) {
 		typ = p.typeContractDefinable()
 	}
 	id := p.consume(lexer.Identifier, "Invalid argument in function def")
@@ -63,6 +63,11 @@ func (p *Parser) functionArgument() FunctionArgument {
 	}
 }
 
+//isUntypedArgument returns true if the argument being parsed is only a name, with no type before it
+func (p *Parser) isUntypedArgument(after TokenType) bool {
+	return p.check(lexer.Identifier) && (after == lexer.Equal || after == lexer.Comma || after == lexer.RParen)
+}
+
 func (p *Parser) isFuncDef() (result bool) {
 	closing := p.findParenClosingPoint(p.current)
 	return p.tokens[closing+1].TokenType == lexer.Arrow ||
@@ -6,7 +6,7 @@ import (
 
 type GenericContract struct {
 	Identifier string
-	Contract   Type
+	Contract   Type //Can be nil - the type parameter accepts Any
 }
 
 func (p *Parser) generic() (contracts []GenericContract) {
@@ -25,8 +25,10 @@ func (p *Parser) generic() (contracts []GenericContract) {
 
 func (p *Parser) genericContract() (typContract GenericContract) {
 	typID := p.consume(lexer.Identifier, "Expected identifier for generic type")
-	p.consume(lexer.Colon, "Expected colon after generic type id")
-	contract := p.typeContractDefinable()
+	var contract Type
+	if p.match(lexer.Colon) {
+		contract = p.typeContractDefinable()
+	}
 	typContract = GenericContract{
 		Identifier: string(typID.Text),
 		Contract:   contract,
@@ -37,11 +39,17 @@ func (p *Parser) genericContract() (typContract GenericContract) {
 func (p *Parser) typeStatement() (typStmt Stmt) {
 	p.consume(lexer.Type, "Expected 'type' at the start of type declaration")
 	id := p.consume(lexer.Identifier, "Expected identifier for type")
+	var generics []GenericContract
+	if p.check(lexer.LAngle) {
+		generics = p.generic()
+	}
 	p.consume(lexer.Equal, "Expected equals after type identifier")
 	contract := p.typeContractDefinable()
 	typStmt = TypeStmt{
 		Identifier: string(id.Text),
+		Generics:   generics,
 		Contract:   contract,
+		Position:   id.Position,
 	}
 	return
 }
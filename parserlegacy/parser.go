@@ -165,6 +165,22 @@ func (p *Parser) cleanNewLines() {
 	for p.match(lexer.NEWLINE) {
 	}
 }
+//cleanNewLinesBefore skips new lines only if they are followed by one of the given types, so that the statement can continue
+func (p *Parser) cleanNewLinesBefore(types ...TokenType) {
+	i := p.current
+	for i < len(p.tokens) && p.tokens[i].TokenType == lexer.NEWLINE {
+		i++
+	}
+	if i == len(p.tokens) {
+		return
+	}
+	for _, t := range types {
+		if p.tokens[i].TokenType == t {
+			p.current = i
+			return
+		}
+	}
+}
 func (p *Parser) insert(index int, value ...Token) {
 	if len(p.tokens) == index {
 		p.tokens = append(p.tokens, value...)
@@ -7,7 +7,8 @@ type Stmt interface {
 }
 
 type ExpressionStmt struct {
-	Expr Expr
+	Expr     Expr
+	Position lexer.Position
 }
 
 type BlockStmt struct {
@@ -21,32 +22,40 @@ type VarDefStmt struct {
 	Identifier string
 	Type       Type
 	Value      Expr
+	Position   lexer.Position
 }
 
 type StructDefStmt struct {
 	Identifier   string
+	Generics     []GenericContract
 	StructFields []StructField
+	Position     lexer.Position
 }
 
 type IfElseStmt struct {
 	Condition  Expr
 	MainBranch Stmt
 	ElseBranch Stmt
+	Position   lexer.Position
 }
 
 type WhileStmt struct {
 	Condition Expr
 	Body      Stmt
+	Position  lexer.Position
 }
 
 type ExtendStmt struct {
 	Identifier string
 	Body       BlockStmt
 	Alias      string
+	Position   lexer.Position
 }
 type TypeStmt struct {
 	Identifier string
+	Generics   []GenericContract
 	Contract   Type
+	Position   lexer.Position
 }
 type GenerifiedStmt struct {
 	Contracts []GenericContract
@@ -55,6 +64,23 @@ type GenerifiedStmt struct {
 
 type ReturnStmt struct {
 	Returning Expr
+	Position  lexer.Position
+}
+
+type ThrowStmt struct {
+	Error Expr
+}
+
+type TryStmt struct {
+	Body    BlockStmt
+	Catches []CatchClause
+	Finally Stmt //Can be nil
+}
+
+type CatchClause struct {
+	Identifier string
+	Type       Type //Can be nil - catches any error
+	Body       BlockStmt
 }
 
 func (ExpressionStmt) stmtNode() {}
@@ -67,6 +93,8 @@ func (ExtendStmt) stmtNode()     {}
 func (GenerifiedStmt) stmtNode() {}
 func (TypeStmt) stmtNode()       {}
 func (ReturnStmt) stmtNode()     {}
+func (ThrowStmt) stmtNode()      {}
+func (TryStmt) stmtNode()        {}
 
 func (p *Parser) declaration() (stmt Stmt) {
 	if p.check(lexer.Let) {
@@ -93,6 +121,10 @@ func (p *Parser) statement() Stmt {
 		return p.returnStatement()
 	case lexer.Extend:
 		return p.extendStatement()
+	case lexer.Throw:
+		return p.throwStatement()
+	case lexer.Try:
+		return p.tryStatement()
 	default:
 		return p.exprStatement()
 	}
@@ -130,25 +162,31 @@ func (p *Parser) varDefStatement() Stmt {
 		Identifier: string(id.Text),
 		Type:       typ,
 		Value:      expr,
+		Position:   id.Position,
 	}
 }
 
 func (p *Parser) whileStatement() Stmt {
-	p.consume(lexer.While, "Expected while at beginning of while loop")
+	tok := p.consume(lexer.While, "Expected while at beginning of while loop")
 	expr := p.expression()
 	body := p.blockStatement()
 	return WhileStmt{
 		Condition: expr,
 		Body:      body,
+		Position:  tok.Position,
 	}
 }
 
 func (p *Parser) ifStatement() (stmt Stmt) {
-	p.consume(lexer.If, "Expected if at beginning of if statement")
+	tok := p.consume(lexer.If, "Expected if at beginning of if statement")
 	condition := p.logicalOr()
 	p.cleanNewLines()
 	mainBranch := p.blockStatement()
+	beforeNewLines := p.current
 	p.cleanNewLines()
+	if !p.check(lexer.Else) {
+		p.current = beforeNewLines //The new line ends the if statement
+	}
 
 	var elseBranch Stmt
 	if p.match(lexer.Else) {
@@ -162,6 +200,7 @@ func (p *Parser) ifStatement() (stmt Stmt) {
 		Condition:  condition,
 		MainBranch: mainBranch,
 		ElseBranch: elseBranch,
+		Position:   tok.Position,
 	}
 	return
 }
@@ -198,23 +237,75 @@ func (p *Parser) blockedDeclaration(errors *[]ParseError) (s Stmt) {
 
 func (p *Parser) structStatement() Stmt {
 	p.consume(lexer.Struct, "Expected struct start to begin with `struct` keyword")
+	id := p.consume(lexer.Identifier, "Expected identifier after `struct` keyword")
+	var generics []GenericContract
+	if p.check(lexer.LAngle) {
+		generics = p.generic()
+	}
 	return StructDefStmt{
-		Identifier:   string(p.consume(lexer.Identifier, "Expected identifier after `struct` keyword").Text),
+		Identifier:   string(id.Text),
+		Generics:     generics,
 		StructFields: p.structFields(),
+		Position:     id.Position,
 	}
 }
 
 func (p *Parser) returnStatement() Stmt {
-	p.consume(lexer.Return, "Expected return")
+	tok := p.consume(lexer.Return, "Expected return")
 	var expr Expr
 	if p.peek().TokenType != lexer.NEWLINE {
 		expr = p.expression()
 	}
-	return ReturnStmt{Returning: expr}
+	return ReturnStmt{Returning: expr, Position: tok.Position}
+}
+
+func (p *Parser) throwStatement() Stmt {
+	p.consume(lexer.Throw, "Expected throw")
+	return ThrowStmt{Error: p.expression()}
+}
+
+func (p *Parser) tryStatement() Stmt {
+	p.consume(lexer.Try, "Expected try at beginning of try statement")
+	body := p.blockStatement()
+	p.cleanNewLinesBefore(lexer.Catch, lexer.Finally)
+
+	catches := make([]CatchClause, 0)
+	for p.match(lexer.Catch) {
+		p.consume(lexer.LParen, "Expected ( after catch")
+		id := p.consume(lexer.Identifier, "Expected identifier for caught error")
+		var typ Type
+		if p.match(lexer.Colon) {
+			typ = p.typeContract()
+		}
+		p.consume(lexer.RParen, "Expected ) after caught error")
+		catches = append(catches, CatchClause{
+			Identifier: string(id.Text),
+			Type:       typ,
+			Body:       p.blockStatement(),
+		})
+		p.cleanNewLinesBefore(lexer.Catch, lexer.Finally)
+	}
+
+	var finally Stmt
+	if p.match(lexer.Finally) {
+		finally = p.blockStatement()
+	}
+	if len(catches) == 0 && finally == nil {
+		panic(ParseError{
+			token:   p.peek(),
+			message: "Expected catch or finally after try block",
+		})
+	}
+	return TryStmt{
+		Body:    body,
+		Catches: catches,
+		Finally: finally,
+	}
 }
 
 func (p *Parser) exprStatement() Stmt {
-	return ExpressionStmt{Expr: p.expression()}
+	position := p.peek().Position
+	return ExpressionStmt{Expr: p.expression(), Position: position}
 }
 
 func (p *Parser) extendStatement() Stmt {
@@ -230,5 +321,6 @@ func (p *Parser) extendStatement() Stmt {
 		Identifier: string(id.Text),
 		Body:       p.blockStatement(),
 		Alias:      alias,
+		Position:   id.Position,
 	}
 }
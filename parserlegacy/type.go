@@ -24,6 +24,11 @@ type BinaryTypeContract struct {
 	Rhs    Type
 }
 
+type GenericTypeContract struct {
+	Identifier string
+	TypeArgs   []Type
+}
+
 type CollectionTypeContract struct {
 	ElemType Type
 }
@@ -33,6 +38,10 @@ type MapTypeContract struct {
 	ValueType Type
 }
 
+type OptionalTypeContract struct {
+	Type Type
+}
+
 func (p *Parser) typeContract() (contract Type) {
 	return p.contractualOr(false)
 }
@@ -72,14 +81,33 @@ func (p *Parser) contractualAnd(allowDef bool) (contract Type) {
 }
 
 func (p *Parser) primaryContract(allowDef bool) (contract Type) {
+	contract = p.nonOptionalContract(allowDef)
+	if p.match(lexer.QuestionMark) {
+		contract = OptionalTypeContract{Type: contract}
+	}
+	return
+}
+
+func (p *Parser) nonOptionalContract(allowDef bool) (contract Type) {
 	if p.peek().TokenType == lexer.LSquare {
 		p.advance()
-		collType := p.consume(lexer.Identifier, "Expected identifier after [ for collection type")
+		elemType := p.typeContract()
 		p.consume(lexer.RSquare, "Expected ] after [ for collection type")
-		return CollectionTypeContract{ElemType: ElementaryTypeContract{Identifier: string(collType.Text)}}
+		return CollectionTypeContract{ElemType: elemType}
 	}
 	if p.peek().TokenType == lexer.Identifier {
 		name := string(p.advance().Text)
+		if p.match(lexer.LAngle) {
+			args := make([]Type, 0)
+			for {
+				args = append(args, p.typeContract())
+				if !p.match(lexer.Comma) {
+					break
+				}
+			}
+			p.consume(lexer.RAngle, "Expected > after type arguments")
+			return GenericTypeContract{Identifier: name, TypeArgs: args}
+		}
 		return ElementaryTypeContract{Identifier: name}
 	} else if p.check(lexer.LParen) {
 		isFunc := p.isFuncDef()
@@ -109,6 +137,9 @@ func (p *Parser) primaryContract(allowDef bool) (contract Type) {
 			return
 		}
 	}
+	if allowDef && p.check(lexer.LBrace) && p.isNamedPropertyContract() {
+		return p.definedContract(allowDef)
+	}
 	if p.peek().TokenType == lexer.LBrace {
 		p.advance()
 		//Peek until reaching a closing brace
@@ -161,5 +192,7 @@ func (t ElementaryTypeContract) typeOf() {}
 func (t BinaryTypeContract) typeOf()     {}
 func (t InvocableTypeContract) typeOf()  {}
 func (t DefinedTypeContract) typeOf()    {}
+func (t GenericTypeContract) typeOf()    {}
 func (t CollectionTypeContract) typeOf() {}
 func (t MapTypeContract) typeOf()        {}
+func (t OptionalTypeContract) typeOf()   {}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/interpreter"
+	"strings"
+	"testing"
+)
+
+func TestLoopsDeclareVariablesEachIteration(t *testing.T) {
+	code := `let mut i = 0
+let mut total = 0
+while i != 3 {
+    let doubled = i * 2
+    total = total + doubled
+    i = i + 1
+}
+total
+let mut readers = [() => 0]
+let mut j = 1
+while j != 4 {
+    let captured = j * 10
+    readers = readers + [() => captured]
+    j = j + 1
+}
+readers[1]()
+readers[3]()`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(6),
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(10),
+		interpreter.IntValue(30),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestBlocksShadowVariables(t *testing.T) {
+	code := `let x = "outer"
+if true {
+    let x = "if"
+    x
+}
+let describe(Boolean flag) => {
+    if flag {
+        let x = "returned"
+        return x
+    }
+    let inner = if flag {
+        let x = "never"
+        x
+    } else {
+        let x = "else"
+        x
+    }
+    inner + " " + x
+}
+describe(true)
+describe(false)
+try {
+    throw "error"
+} catch (e) {
+    let e = "shadowed"
+    e
+}`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.StringValue("if"),
+		nil,
+		interpreter.StringValue("returned"),
+		interpreter.StringValue("else outer"),
+		interpreter.StringValue("shadowed"),
+	}
+	expectBothEngines(t, code, expectedResults)
+
+	errors, warnings := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors)
+	if len(warnings) != 5 {
+		t.Fatalf("Expected 5 warnings but got %v", warnings)
+	}
+	for _, warning := range warnings {
+		if !strings.Contains(warning.Message, "shadows a variable outside of the block") {
+			t.Errorf("Unexpected warning %v", warning)
+		}
+	}
+}
+
+func TestBlockVariablesDoNotLeak(t *testing.T) {
+	programs := []string{
+		`if true {
+    let hidden = 1
+}
+hidden`,
+		`let mut i = 0
+while i != 1 {
+    let hidden = i
+    i = i + 1
+}
+hidden`,
+		`let value = if true {
+    let hidden = 1
+    hidden
+} else {
+    2
+}
+hidden`,
+	}
+	for _, code := range programs {
+		for _, options := range [][]interpreter.Option{nil, {interpreter.WithBytecode()}} {
+			err := fmt.Sprint(recovered(code, options...))
+			if !strings.Contains(err, "No such variable or parameter or constructor hidden") {
+				t.Errorf("Expected hidden to be out of scope in %s, got %s", code, err)
+			}
+		}
+	}
+}
+
+func TestReturnsInsideLoopsReturnFromTheFunction(t *testing.T) {
+	code := `let firstOver(Int limit) => {
+    let mut i = 0
+    while i != 10 {
+        let squared = i * i
+        if squared != limit {
+            i = i + 1
+        } else {
+            return "found " + i
+        }
+        while true {
+            return "below " + i
+        }
+    }
+    "none"
+}
+firstOver(0)
+firstOver(4)
+let mut seen = 0
+let countTo(Int n) => {
+    let mut i = 0
+    while true {
+        i = i + 1
+        seen = seen + 1
+        if i == n {
+            return i
+        }
+    }
+}
+countTo(3)
+seen`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.StringValue("found 0"),
+		interpreter.StringValue("below 1"),
+		nil,
+		nil,
+		interpreter.IntValue(3),
+		interpreter.IntValue(3),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestReturnsInsideIfExpressionsReturnFromTheFunction(t *testing.T) {
+	code := `let mut log = ""
+let unscoped(Boolean flag) => {
+    let a = if flag {
+        log = log + "a"
+        return "returned"
+        log = log + "b"
+        "result"
+    } else {
+        "other"
+    }
+    a
+}
+let scoped(Boolean flag) => {
+    let a = if flag {
+        let message = "returned in scope"
+        return message
+        "result"
+    } else {
+        let message = "other in scope"
+        message
+    }
+    a
+}
+unscoped(true)
+unscoped(false)
+scoped(true)
+scoped(false)
+log`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("returned"),
+		interpreter.StringValue("other"),
+		interpreter.StringValue("returned in scope"),
+		interpreter.StringValue("other in scope"),
+		interpreter.StringValue("a"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
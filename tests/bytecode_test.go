@@ -0,0 +1,265 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"reflect"
+	"testing"
+)
+
+//bytecodePrograms are run by both the tree walker and the VM, which should give the same results
+var bytecodePrograms = map[string]string{
+	"arithmetic": `let x = 3
+let y = x * 4 + 2
+y - x`,
+	"recursion": lookupCode,
+	"closures": `let adder(Int a) => (Int b) => a + b
+let addTwo = adder(2)
+addTwo(5)
+adder(10)(1)`,
+	"mutation": `let mut counter = 0
+let increment() => {
+    counter = counter + 1
+    counter
+}
+increment()
+increment()
+counter`,
+	"ifExpressions": `let sign(Int n) => {
+    return if n == 0 => "zero" else => "non zero"
+}
+sign(0)
+sign(3)
+let b = if true => "yes" else => "no"
+b`,
+	"ifExpressionStatements": `let mut log = ""
+let f() => {
+    let a = if true {
+        log = log + "a"
+        return "ignored"
+        log = log + "b"
+        "result"
+    } else {
+        "other"
+    }
+    a
+}
+f()
+log`,
+	"returnInsideWhile": `let loop() => {
+    let mut i = 0
+    let mut seen = 0
+    while i != 3 {
+        i = i + 1
+        return i
+        seen = seen + 1
+    }
+    seen
+}
+loop()`,
+	"exceptions": `let mut log = ""
+let risky(Int n) => Int {
+    if n == 0 {
+        throw IOError("zero")
+    }
+    n
+}
+let safe(Int n) => {
+    try {
+        return risky(n)
+    } catch (e: IOError) {
+        log = log + e.message
+        return 0
+    } finally {
+        log = log + "!"
+    }
+}
+safe(0)
+safe(5)
+log`,
+	"results": `let parse(String s) => Result<Int, String> {
+    if s == "1" {
+        return Ok(1)
+    }
+    return Err("bad input")
+}
+let addOne(String s) => Result<Int, String> {
+    let n = parse(s)?
+    Ok(n + 1)
+}
+addOne("1")
+addOne("x")`,
+	"extensions": `struct Person {
+    String name
+    mut Int age
+}
+let age = 100
+extend Person {
+    let greeting => "Happy Birthday " + name + "!"
+    let celebrateBirthday => {
+        age += 1
+        greeting()
+    }
+}
+let bob = Person("Bob", 30)
+bob.celebrateBirthday()
+bob.age
+age`,
+	"match": matchStructs + `let describe(Cat | Dog | Int? value) => {
+  let description = match value {
+    Cat => value.name
+    Dog => "Dog of " + value.owner
+    null => "Nothing"
+    else => "Number"
+  }
+  description
+}
+describe(Cat("Tom"))
+describe(Dog("Jon"))
+describe(null)
+describe(2)`,
+	"overloads": `let show(Int i) => "Int"
+let show(String s) => "String"
+show(1)
+show("a")`,
+	"operators": `struct Pair {
+    Int first
+    Int second
+}
+let pair = Pair(1, 2)
+let isOne(Int n) => n == 1
+let check(Boolean b) => b
+pair.first == 1 && pair.second == 2
+isOne(pair.second) && check(true)
+isOne(pair.first) || check(false)
+!isOne(pair.second)
+[pair.first, pair.second, [1, 2].size][2]`,
+	"collections": `let numbers = [1, 2, 3]
+let square = (Int n) => n * n
+square(numbers[2])
+let ages = {"Bob": 30}
+ages["Bob"]`,
+}
+
+func executeBoth(code string) (walked []*interpreter.Value, compiled []*interpreter.Value) {
+	walked, _, _, _ = base.Execute(nil, code, false)
+	compiled, _, _, _ = base.Execute(nil, code, false, interpreter.WithBytecode())
+	return walked, compiled
+}
+
+func TestBytecodeMatchesTreeWalker(t *testing.T) {
+	for name, code := range bytecodePrograms {
+		walked, compiled := executeBoth(code)
+		if len(walked) == 0 {
+			t.Errorf("Program %s did not run", name)
+			continue
+		}
+		if !reflect.DeepEqual(walked, compiled) {
+			t.Errorf("Bytecode output of %s differs, expected %s but got %s", name, formatValues(walked), formatValues(compiled))
+		}
+	}
+}
+
+//recovered runs code and returns what it panicked with
+func recovered(code string, options ...interpreter.Option) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	base.Execute(nil, code, false, options...)
+	return nil
+}
+
+func TestBytecodeErrorsMatchTreeWalker(t *testing.T) {
+	programs := []string{
+		`let x = 1
+let x = 2`,
+		`let f(Int a) => a
+let f(Int b) => b`,
+		`if 1 {
+    2
+}`,
+		`let mut i = 0
+while i {
+    i = 1
+}`,
+		`let b = if false => 1`,
+		`let x = 1
+x = 2`,
+		`let f() => missing
+f()`,
+		`1 && true`,
+		`false || 2`,
+		`!3`,
+		`[1, 2][5]`,
+		`struct Empty {
+}
+Empty().missing`,
+	}
+	for _, code := range programs {
+		walked := fmt.Sprint(recovered(code))
+		compiled := fmt.Sprint(recovered(code, interpreter.WithBytecode()))
+		if walked == "<nil>" {
+			t.Errorf("Expected %s to fail", code)
+		}
+		if walked != compiled {
+			t.Errorf("Bytecode error for %s differs, expected %s but got %s", code, walked, compiled)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	code := `let double(Int n) => n * 2
+double(4)`
+	expected := `== line 1 ==
+0000 CHECK_DEFINE            0  ; double
+0003 EXEC                    1  ; function double
+0006 DEFINE                  0  ; double
+
+== double ==
+0000 CONSTANT                0  ; 2
+0003 LOAD                    0  ; n
+0006 INVOKE_ON               1    1  ; times
+
+== line 2 ==
+0000 CONSTANT                0  ; 4
+0003 INVOKE                  0    1  ; double
+`
+	listing := base.Disassemble(nil, code)
+	if listing != expected {
+		t.Errorf("Incorrect disassembly, got\n%s\nbut expected\n%s", listing, expected)
+	}
+}
+
+func TestCompilingLeavesTheTreeUnchanged(t *testing.T) {
+	code := `let run(Int n) => {
+    let doubled = n * 2
+    try {
+        doubled
+    } catch (e: Error) {
+        0
+    }
+}`
+	stmts, errs := parserlegacy.NewParser(lexer.Lex(code)).Parse()
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected parse errors %v", errs)
+	}
+	command := interpreter.ToCommand(stmts[0])
+	interpreter.NewResolver().Resolve(command)
+	first := interpreter.Compile("run", command).Disassemble()
+	second := interpreter.Compile("run", command).Disassemble()
+	if first != second {
+		t.Errorf("Compiling the same command again gave\n%s\nbut the first time gave\n%s", second, first)
+	}
+}
+
+func BenchmarkBytecodeVariableLookups(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		res, _, _, _ := base.Execute(nil, lookupCode, false, interpreter.WithBytecode())
+		if res[4].Value != int64(610) || res[5].Value != int64(124750) {
+			b.Fail()
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestClosuresCaptureEachCall(t *testing.T) {
+	code := `let adder(Int a) => (Int b) => a + b
+let addTwo = adder(2)
+let addTen = adder(10)
+addTwo(1)
+addTen(1)
+let outer() => {
+    let call() => later()
+    let later() => "later"
+    call
+}
+outer()()`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(3),
+		interpreter.IntValue(11),
+		nil,
+		interpreter.StringValue("later"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestClosureCounters(t *testing.T) {
+	code := `let makeCounter() => {
+    let mut count = 0
+    let increment() => {
+        count = count + 1
+        count
+    }
+    increment
+}
+let first = makeCounter()
+let second = makeCounter()
+first()
+first()
+second()
+first()
+let mut total = 0
+let add(Int amount) => {
+    total = total + amount
+}
+add(2)
+add(3)
+total`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(1),
+		interpreter.IntValue(2),
+		interpreter.IntValue(1),
+		interpreter.IntValue(3),
+		nil,
+		nil,
+		interpreter.UnitValue(),
+		interpreter.UnitValue(),
+		interpreter.IntValue(5),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestClosuresCreatedInLoops(t *testing.T) {
+	code := `let adder(Int a) => (Int b) => a + b
+let adders() => {
+    let mut result = [adder(0)]
+    let mut i = 1
+    while i != 3 {
+        result = result + [adder(i)]
+        i = i + 1
+    }
+    result
+}
+let counters(Int start) => {
+    let mut result = [() => 0]
+    let mut i = start
+    while i != start + 2 {
+        result = result + [() => i]
+        i = i + 1
+    }
+    result
+}
+let made = adders()
+made[1](10)
+made[2](10)
+let fromOne = counters(1)
+let fromFive = counters(5)
+fromOne[1]()
+fromFive[1]()`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(11),
+		interpreter.IntValue(12),
+		nil,
+		nil,
+		interpreter.IntValue(3), //The loop variable is captured by reference, so every closure sees its last value
+		interpreter.IntValue(7),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+//expectBothEngines runs code with the tree walker and on the VM, and checks that both give the expected results
+func expectBothEngines(t *testing.T, code string, expectedResults []*interpreter.Value) {
+	t.Helper()
+	walked, compiled := executeBoth(code)
+	if !reflect.DeepEqual(walked, expectedResults) {
+		t.Errorf("Incorrect output, got %s but expected %s", formatValues(walked), formatValues(expectedResults))
+	}
+	if !reflect.DeepEqual(compiled, expectedResults) {
+		t.Errorf("Incorrect bytecode output, got %s but expected %s", formatValues(compiled), formatValues(expectedResults))
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestCompoundAssignment(t *testing.T) {
+	code := `let mut a = 5
+a += 3
+a -= 1
+a *= 4
+a /= 2
+a %= 5
+a`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(4),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect compound assignment output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestIncrementAndDecrement(t *testing.T) {
+	code := `let mut a = 5
+a++
+a++
+a--
+a`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(6),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect increment output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestCompoundAssignmentUsesOverloadedOperator(t *testing.T) {
+	code := `struct Money {
+    Int cents
+}
+extend Money {
+    let plus(Money other) => Money(this.cents + other.cents)
+}
+let mut total = Money(1)
+total += Money(2)
+total.cents`
+	results, _, _, _ := base.Execute(nil, code, false)
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+	if !reflect.DeepEqual(results[4], interpreter.IntValue(3)) {
+		t.Errorf("Incorrect overloaded compound assignment output, got %s but expected 3", results[4].String())
+	}
+}
+
+func TestCompoundAssignmentOnImmutableVariable(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows compound assignment of an immutable variable")
+		}
+	}()
+
+	code := `let a = 5
+a += 1`
+	base.Execute(nil, code, false)
+}
+
+func TestCompoundPropertyAssignmentEvaluatesReceiverOnce(t *testing.T) {
+	code := `struct Counter {
+    mut Int value
+}
+let counter = Counter(0)
+let mut lookups = 0
+let find() => {
+    lookups += 1
+    counter
+}
+find().value += 5
+find().value++
+counter.value
+lookups`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(6),
+		interpreter.IntValue(2),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestCatchThrownError(t *testing.T) {
+	code := `let mut log = ""
+try {
+    throw IOError("file missing")
+} catch (e: TypeError) {
+    log = "wrong catch"
+} catch (e: IOError) {
+    log = e.type + ": " + e.message
+} finally {
+    log = log + "!"
+}
+log`
+	results, _, _, _ := base.Execute(nil, code, false)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	expected := interpreter.StringValue("IOError: file missing!")
+	if !reflect.DeepEqual(results[2], expected) {
+		t.Errorf("Incorrect try/catch output, got %s but expected %s", results[2].String(), expected.String())
+	}
+}
+
+func TestCatchInternalError(t *testing.T) {
+	code := `let fail() => Int {
+    missing
+}
+try {
+    fail()
+} catch (e: NameError) {
+    e.stackTrace
+}
+try {
+    let x: Int = "Hello"
+} catch (e) {
+    e.type
+}`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expected := "[<nil>, [fail], TypeError]"
+
+	if formatValues(results) != expected {
+		t.Errorf("Incorrect internal error output, got %v but expected %v", formatValues(results), expected)
+	}
+}
+
+func TestUncaughtErrorPropagates(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok {
+			t.Fatalf("Expected an uncaught error, got %v", r)
+		}
+		if thrown.Value.Type != interpreter.IOErrorType {
+			t.Errorf("Expected an uncaught IOError, got %s", thrown.Value.Type.Name())
+		}
+	}()
+
+	code := `try {
+    throw IOError("file missing")
+} catch (e: TypeError) {
+    e
+}`
+	base.Execute(nil, code, false)
+}
+
+func TestCallStacksAreSeparatePerInterpreter(t *testing.T) {
+	code := `let inner(Int depth) => {
+    if depth == 0 {
+        throw IOError("bottom")
+    }
+    inner(depth - 1) + 1
+}
+let outer() => {
+    try {
+        inner(20)
+    } catch (e) {
+        return e.stackTrace.size
+    }
+    0
+}
+outer()
+outer()`
+	expected := "[<nil>, <nil>, 22, 22]"
+	results := make(chan string)
+	for i := 0; i < 8; i++ {
+		go func() {
+			values, _, _, _ := base.Execute(nil, code, false)
+			results <- formatValues(values)
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if output := <-results; output != expected {
+			t.Errorf("Incorrect concurrent stack trace output, got %v but expected %v", output, expected)
+		}
+	}
+}
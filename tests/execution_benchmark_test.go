@@ -12,7 +12,34 @@ func BenchmarkSimpleExecution(b *testing.B) {
 
 	for n := 0; n < b.N; n++ {
 		res, _, _, _ := base.Execute(nil, code, false)
-		if res[1].Value != "yes" {
+		if res[1].String() != "yes" {
+			b.Fail()
+		}
+	}
+}
+
+var lookupCode = `let fib(Int n) => Int {
+    if n == 0 {
+        return 0
+    }
+    if n == 1 {
+        return 1
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+let mut i = 0
+let mut total = 0
+while i != 500 {
+    total = total + i
+    i = i + 1
+}
+fib(15)
+total`
+
+func BenchmarkVariableLookups(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		res, _, _, _ := base.Execute(nil, lookupCode, false)
+		if res[4].Value != int64(610) || res[5].Value != int64(124750) {
 			b.Fail()
 		}
 	}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestImplicitReceiverMembers(t *testing.T) {
+	code := `struct Person {
+    String name
+    mut Int age
+}
+let age = 100
+extend Person {
+    let greeting => "Happy Birthday " + name + "!"
+    let celebrateBirthday => {
+        age += 1
+        greeting()
+    }
+}
+let bob = Person("Bob", 30)
+bob.celebrateBirthday()
+bob.age
+age`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Happy Birthday Bob!"),
+		interpreter.IntValue(31),
+		interpreter.IntValue(100),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect extension output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestLocalsShadowReceiverMembers(t *testing.T) {
+	code := `struct Person {
+    String name
+}
+extend Person {
+    let nickname => {
+        let name = "Bobby"
+        name
+    }
+}
+Person("Bob").nickname()`
+	results, _, _, _ := base.Execute(nil, code, false)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !reflect.DeepEqual(results[2], interpreter.StringValue("Bobby")) {
+		t.Errorf("Incorrect shadowed extension output, got %s but expected Bobby", results[2].String())
+	}
+}
+
+func TestMostSpecificExtension(t *testing.T) {
+	code := `struct Person {
+    String name
+}
+extend Person {
+    let describe => "Person " + name
+    struct Student {
+        String major
+    }
+}
+extend Student {
+    let describe => "Student " + name
+}
+extend Any {
+    let describe => "Something"
+}
+let three = 3
+Person("Alice").describe()
+Student("Bob", "Maths").describe()
+three.describe()`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Person Alice"),
+		interpreter.StringValue("Student Bob"),
+		interpreter.StringValue("Something"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect extension resolution output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestExtensionOnUnionAndEquivalentCollectionType(t *testing.T) {
+	code := `type Number = Int | Float
+extend Number {
+    let double => this * 2
+}
+extend String {
+    let shout => this + "!"
+}
+let three = 3
+three.double()
+"hey".shout()`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(6),
+		interpreter.StringValue("hey!"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect extension resolution output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestAmbiguousExtension(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows ambiguous extension calls")
+		}
+	}()
+
+	code := `struct Named {
+    String name
+}
+struct Aged {
+    Int age
+}
+struct Person {
+    String name
+    Int age
+}
+extend Named {
+    let describe => "Named"
+}
+extend Aged {
+    let describe => "Aged"
+}
+Person("Alice", 20).describe()`
+	base.Execute(nil, code, false)
+}
+
+func TestExtensionParametersFollowTheReceiver(t *testing.T) {
+	code := `struct Point {
+    Int x
+    Int y
+}
+extend Point {
+    let moved(Int dx, Int dy) => Point(x + dx, y + dy)
+}
+let p = Point(1, 2).moved(10, 20)
+p.x
+p.y`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(11),
+		interpreter.IntValue(22),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFunctionSubtyping(t *testing.T) {
+	code := `let f: (Any) => Int = (Any x) => Int {
+  return 1
+}
+let g: (Int) => Any = f
+let h: (Int) => Int = (Int x) => x * 2
+g(3)
+h(4)`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(1),
+		interpreter.IntValue(8),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect function subtyping output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestFunctionParameterCovarianceRejected(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "parameter 1 has type Int, which cannot accept every Any") {
+			t.Errorf("Expected a parameter variance error, got %v", r)
+		}
+	}()
+
+	code := `let f: (Any) => Int = (Int x) => Int {
+  return x
+}`
+	base.Execute(nil, code, false)
+}
+
+func TestFunctionReturnContravarianceRejected(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "it returns Any, which is not accepted by Int") {
+			t.Errorf("Expected a return variance error, got %v", r)
+		}
+	}()
+
+	code := `let f: (Int) => Int = (Int x) => Any {
+  return x
+}`
+	base.Execute(nil, code, false)
+}
+
+func TestSetTimeoutCallbackArity(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "expected a function taking 0 parameters, but it takes 1") {
+			t.Errorf("Expected a callback arity error, got %v", r)
+		}
+	}()
+
+	base.Execute(nil, "setTimeout((Int x) => x, 0)", false)
+}
+
+func TestUnitFunctionTypeAcceptsAnyReturnType(t *testing.T) {
+	code := `let run(() => Unit callback) => callback()
+let ignore: (Int) => Unit = (Int x) => Int {
+  return x
+}
+run(() => 5)
+ignore(3)`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.IntValue(5),
+		interpreter.IntValue(3),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestUndeclaredReturnTypeComparedWithExpected(t *testing.T) {
+	code := `let increment(Int x) => x + 1
+let functions: [(Int) => Int] = [increment, (x) => x * 2]
+functions[0](1)
+functions[1](4)`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.IntValue(2),
+		interpreter.IntValue(8),
+	}
+	expectBothEngines(t, code, expectedResults)
+
+	wrong := `let functions: [(Int) => Int] = [(Int x) => "s"]
+functions[0](1)`
+	for _, options := range [][]interpreter.Option{nil, {interpreter.WithBytecode()}} {
+		err := fmt.Sprint(recovered(wrong, options...))
+		if !strings.Contains(err, "did not return value of type Int, instead was [Char]") {
+			t.Errorf("Expected the return type of the function in the collection to be checked, got %s", err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenericFunction(t *testing.T) {
+	code := `<T>
+let identity(T value) => T {
+    value
+}
+identity(3)
+identity("Hello")`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(3),
+		interpreter.StringValue("Hello"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect generic function output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestGenericFunctionWithUnsatisfiedContract(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows type arguments that do not fulfill their contract")
+		}
+	}()
+
+	code := `<T: Int | Float>
+let identity(T value) => T {
+    value
+}
+identity("Hello")`
+	base.Execute(nil, code, false)
+}
+
+func TestUnsatisfiedContractIsCatchable(t *testing.T) {
+	code := `<T: Int | Float>
+let identity(T value) => T {
+    value
+}
+let attempt() => {
+    try {
+        identity("Hello")
+    } catch (e: TypeError) {
+        return e.message
+    }
+    "accepted"
+}
+attempt()`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.StringValue("Type [Char] does not fulfill the contract Int | Float of type parameter T"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestGenericStruct(t *testing.T) {
+	code := `struct Box<T> {
+    T value
+}
+let box: Box<Int> = Box(3)
+box
+box.value`
+	results, _, _, _ := base.Execute(nil, code, false)
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	if results[2].Type.Name() != "Box<Int>" {
+		t.Errorf("Incorrect generic struct type, got %s but expected Box<Int>", results[2].Type.Name())
+	}
+	if !reflect.DeepEqual(results[3], interpreter.IntValue(3)) {
+		t.Errorf("Incorrect generic struct property, got %s but expected 3", results[3].String())
+	}
+}
+
+func TestGenericStructWithMismatchedTypeArgument(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows assignment of Box<Int> to Box<String>")
+		}
+	}()
+
+	code := `struct Box<T> {
+    T value
+}
+let box: Box<String> = Box(3)`
+	base.Execute(nil, code, false)
+}
+
+func TestGenericInstancesOfTypeParametersWithTheSameName(t *testing.T) {
+	code := `struct Person {
+    String name
+}
+struct Box<T> {
+    T value
+}
+<T>
+let describe(Box<T> box) => String {
+    "boxed"
+}
+<T: Person>
+let nameOf(Box<T> box) => String {
+    box.value.name
+}
+describe(Box(1))
+nameOf(Box(Person("Bob")))`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("boxed"),
+		interpreter.StringValue("Bob"),
+	}
+	expectBothEngines(t, code, expectedResults)
+
+	err := fmt.Sprint(recovered(code + "\nnameOf(Box(1))"))
+	if !strings.Contains(err, "Type Int does not fulfill the contract Person of type parameter T") {
+		t.Errorf("Expected Box<T> to keep the Person contract of T, got %s", err)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/interpreter"
+	"strings"
+	"testing"
+)
+
+func TestFunctionsCanBeCalledBeforeTheirDeclaration(t *testing.T) {
+	code := `let first() => second()
+first()
+let second() => 2
+isEven(10)
+let isEven(Int n) => Boolean {
+    if n == 0 {
+        return true
+    }
+    isOdd(n - 1)
+}
+let isOdd(Int n) => Boolean {
+    if n == 0 {
+        return false
+    }
+    isEven(n - 1)
+}`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(2),
+		nil,
+		interpreter.BooleanValue(true),
+		nil,
+		nil,
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestTypesCanBeUsedBeforeTheirDeclaration(t *testing.T) {
+	code := `let isOrigin(Point p) => p.x == 0 && p.y == 0
+isOrigin(Point(0, 0))
+let describe(Number n) => n is Int
+describe(1)
+struct Point {
+    Int x
+    Int y
+}
+type Number = Int | Float`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.BooleanValue(true),
+		nil,
+		interpreter.BooleanValue(true),
+		nil,
+		nil,
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestReadingUninitialisedVariables(t *testing.T) {
+	programs := []string{
+		`let early() => late
+early()
+let late = 3`,
+		`late
+let late = 3`,
+	}
+	for _, code := range programs {
+		for _, options := range [][]interpreter.Option{nil, {interpreter.WithBytecode()}} {
+			err := fmt.Sprint(recovered(code, options...))
+			if !strings.Contains(err, "Variable late was read before it was initialised") {
+				t.Errorf("Expected late to be uninitialised in %s, got %s", code, err)
+			}
+		}
+	}
+
+	code := `let read() => late
+let late = 3
+read()`
+	expectBothEngines(t, code, []*interpreter.Value{nil, nil, interpreter.IntValue(3)})
+}
+
+func TestHoistedRedefinitionsAreReported(t *testing.T) {
+	programs := map[string]string{
+		`let x = 1
+let x() => 2`: "Variable named x already exists",
+		`let f(Int a) => a
+f(1)
+let f(Int b) => b`: "Function f is already defined with the signature",
+	}
+	for code, expected := range programs {
+		err := fmt.Sprint(recovered(code))
+		if !strings.Contains(err, expected) {
+			t.Errorf("Expected %s to fail with %s, got %s", code, expected, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNestedStructInheritsProperties(t *testing.T) {
+	code := `struct Person {
+    String name
+    Int age
+}
+extend Person {
+    let greeting => "Hello " + name
+    struct Student {
+        String major
+    }
+}
+let student = Student("Alice", 20, "Maths")
+student.major
+student.greeting()
+let person: Person = student
+person.age`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Maths"),
+		interpreter.StringValue("Hello Alice"),
+		nil,
+		interpreter.IntValue(20),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect inheritance output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestDerivedErrorIsCaughtByParent(t *testing.T) {
+	code := `extend IOError {
+    struct FileNotFoundError {
+        String path
+    }
+}
+try {
+    throw FileNotFoundError("missing", "a.txt")
+} catch (e: IOError) {
+    e.type
+}`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.StringValue("FileNotFoundError"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect derived error output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestNestedStructWithDuplicateProperty(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows a derived struct to redefine a parent property")
+		}
+	}()
+
+	code := `struct Person {
+    String name
+}
+extend Person {
+    struct Student {
+        String name
+    }
+}`
+	base.Execute(nil, code, false)
+}
+
+func TestDuplicateStructProperties(t *testing.T) {
+	programs := map[string]string{
+		`struct P {
+    Int a
+    Int a
+}`: "Property a of P is defined more than once",
+		`struct Person {
+    String name
+}
+extend Person {
+    struct Student {
+        String name
+    }
+}`: "Property name of Student is already defined by Person",
+	}
+	for code, expected := range programs {
+		err := fmt.Sprint(recovered(code))
+		if !strings.Contains(err, "Uncaught TypeError: "+expected) {
+			t.Errorf("Expected %s to fail with %s, got %s", code, expected, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"testing"
+)
+
+const mixedReceivers = `struct Person {
+    String name
+}
+extend Int {
+    let describe => "Int " + this
+}
+extend String {
+    let describe => "String " + this
+}
+extend Boolean {
+    let describe => "Boolean " + this
+}
+extend Float {
+    let describe => "Float " + this
+}
+extend Char {
+    let describe => "Char " + this
+}
+extend Person {
+    let describe => "Person " + name
+}
+let show(Any value) => value.describe()
+`
+
+func TestReceiverCallSitesWithMixedTypes(t *testing.T) {
+	code := mixedReceivers + `show(1)
+show("a")
+show(2)
+show("b")
+show(true)
+show(1.5)
+show('c')
+show(Person("Bob"))
+show(3)
+show("c")`
+	expectedResults := []*interpreter.Value{
+		nil, nil, nil, nil, nil, nil, nil, nil,
+		interpreter.StringValue("Int 1"),
+		interpreter.StringValue("String a"),
+		interpreter.StringValue("Int 2"),
+		interpreter.StringValue("String b"),
+		interpreter.StringValue("Boolean true"),
+		interpreter.StringValue("Float 1.5"),
+		interpreter.StringValue("Char c"),
+		interpreter.StringValue("Person Bob"),
+		interpreter.StringValue("Int 3"),
+		interpreter.StringValue("String c"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestReceiverFunctionsWithMixedArgumentTypes(t *testing.T) {
+	code := `let add(Any a, Any b) => a + b
+add(1, 2)
+add("x", 1)
+add(1, "y")
+add(3, 4)
+add("x", "y")`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(3),
+		interpreter.StringValue("x1"),
+		interpreter.StringValue("1y"),
+		interpreter.IntValue(7),
+		interpreter.StringValue("xy"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestReceiverCacheSeesLaterExtensions(t *testing.T) {
+	code := `type Number = Int | Float
+extend Number {
+    let kind => "number"
+}
+let kindOf(Any value) => value.kind()
+kindOf(1)
+kindOf(1.5)
+extend Int {
+    let kind => "int"
+}
+kindOf(1)
+kindOf(1.5)`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("number"),
+		interpreter.StringValue("number"),
+		nil,
+		interpreter.StringValue("int"),
+		interpreter.StringValue("number"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestReassignedReceiverFunctionsAreCalled(t *testing.T) {
+	code := `let mut describe = (Any x) => "first " + x
+let call(Any value) => value.describe()
+call(1)
+call("a")
+describe = (Any x) => "second " + x
+call(1)
+call("a")
+call(true)`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.StringValue("first 1"),
+		interpreter.StringValue("first a"),
+		nil,
+		interpreter.StringValue("second 1"),
+		interpreter.StringValue("second a"),
+		interpreter.StringValue("second true"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func BenchmarkPolymorphicReceiverCalls(b *testing.B) {
+	code := mixedReceivers + `let mut i = 0
+let mut last = ""
+while i != 200 {
+    last = show(i)
+    last = show("a")
+    i = i + 1
+}
+last`
+	for n := 0; n < b.N; n++ {
+		res, _, _, _ := base.Execute(nil, code, false)
+		if res[len(res)-1].String() != "String a" {
+			b.Fail()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"github.com/ElaraLang/elara/typer"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLambdaParameterInference(t *testing.T) {
+	code := `let double: (Int) => Int = (x) => x * 2
+let apply((Int) => Int f, Int value) => f(value)
+struct Box {
+  Int value
+}
+extend Box {
+  let map((Int) => Int f) => Box(f(value))
+}
+let mut fired = false
+setTimeout(() => fired = true, 0)
+double(4)
+apply((x) => x + 1, 2)
+Box(3).map((x) => x * 10).value
+fired`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.UnitValue(),
+		interpreter.IntValue(8),
+		interpreter.IntValue(3),
+		interpreter.IntValue(30),
+		interpreter.BooleanValue(true),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect lambda inference output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestInferredLambdaType(t *testing.T) {
+	code := `let double: (Int) => Int = (x) => x * 2
+double`
+	results, _, _, _ := base.Execute(nil, code, false)
+	if results[1].Type.Name() != "(Int) => Int" {
+		t.Errorf("Expected the parameter and return types to be inferred as Int, got %s", results[1].Type.Name())
+	}
+}
+
+func TestInferredLambdaReturnChecked(t *testing.T) {
+	programs := []string{
+		`let f: (Int) => Int = (Int x) => "s"
+f(1)`,
+		`let apply((Int) => Int f) => f(1)
+apply((x) => "s")`,
+	}
+	for _, code := range programs {
+		for _, options := range [][]interpreter.Option{nil, {interpreter.WithBytecode()}} {
+			err := fmt.Sprint(recovered(code, options...))
+			if !strings.Contains(err, "did not return value of type Int, instead was [Char]") {
+				t.Errorf("Expected the return type of the lambda to be checked in %s, got %s", code, err)
+			}
+		}
+	}
+}
+
+func TestInferredLambdaParameterChecked(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "Expected Int for parameter x") {
+			t.Errorf("Expected the inferred parameter type to be checked, got %v", r)
+		}
+	}()
+	base.Execute(nil, `let double: (Int) => Int = (x) => x * 2
+double("4")`, false)
+}
+
+func TestUninferredLambdaFallsBackToAny(t *testing.T) {
+	code := `let identity = (x) => x
+identity("one")
+identity(1)`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.StringValue("one"),
+		interpreter.IntValue(1),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect uninferred lambda output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func typeCheckWithWarnings(t *testing.T, code string) ([]typer.TypeError, []typer.TypeWarning) {
+	stmts, errs := parserlegacy.NewParser(lexer.Lex(code)).Parse()
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected syntax errors %v", errs)
+	}
+	checker := typer.NewTyper(stmts)
+	return checker.HandleTyping(), checker.Warnings
+}
+
+func TestTyperInfersLambdaParameters(t *testing.T) {
+	code := `let square(Int x) => x * x
+let apply((String) => Int f) => f("2")
+let fromString: (String) => Int = (s) => square(s)
+apply((s) => square(s))`
+	errors, warnings := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors,
+		"Expected Int for parameter x of square and got [Char]",
+		"Expected Int for parameter x of square and got [Char]")
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings but got %v", warnings)
+	}
+}
+
+func TestTyperWarnsAboutUninferredParameters(t *testing.T) {
+	code := `let functions = [(x) => x]
+let run((Int) => Int f) => f(1)
+let run((Int) => Int f, Int x) => f(x)
+run((y) => y)`
+	errors, warnings := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "The type of parameter x of <anonymous> could not be inferred") {
+		t.Errorf("Expected a single warning about x but got %v", warnings)
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"strings"
+	"testing"
+)
+
+func TestCollectionLiteralTypes(t *testing.T) {
+	code := `[1, 2]
+[1, "a", 2]
+[1, null]
+let xs: [Int | String] = [1, "a"]
+xs`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedTypes := []string{"[Int]", "[Int | [Char]]", "[Int?]", "", "[Int | [Char]]"}
+
+	for i, expected := range expectedTypes {
+		if expected == "" {
+			continue
+		}
+		if results[i].Type.Name() != expected {
+			t.Errorf("Incorrect type for collection literal %d, got %s but expected %s", i, results[i].Type.Name(), expected)
+		}
+	}
+}
+
+func TestMapLiteralTypes(t *testing.T) {
+	code := `let a = {1 : "a", 2 : "b"}
+let b = {1 : "a", "b" : 2}
+a
+b`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedTypes := []string{"{ Int : [Char] }", "{ Int | [Char] : [Char] | Int }"}
+
+	for i, expected := range expectedTypes {
+		if results[i+2].Type.Name() != expected {
+			t.Errorf("Incorrect type for map literal %d, got %s but expected %s", i, results[i+2].Type.Name(), expected)
+		}
+	}
+}
+
+func TestStructLiteralCommonAncestor(t *testing.T) {
+	code := `struct Animal {
+    String name
+}
+extend Animal {
+    struct Dog {
+    }
+    struct Cat {
+    }
+}
+[Dog("Rex"), Cat("Tom")]`
+	results, _, _, _ := base.Execute(nil, code, false)
+	if results[2].Type.Name() != "[Animal]" {
+		t.Errorf("Incorrect type for collection of sibling structs, got %s but expected [Animal]", results[2].Type.Name())
+	}
+}
+
+func TestMixedCollectionRejected(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "[Int | [Char]] in place of [Int]") {
+			t.Errorf("Expected a type error for a mixed collection, got %v", r)
+		}
+	}()
+
+	base.Execute(nil, `let xs: [Int] = [1, "a"]`, false)
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const matchStructs = `struct Cat {
+  String name
+}
+struct Dog {
+  String owner
+}
+`
+
+func TestMatch(t *testing.T) {
+	code := matchStructs + `let describe(Cat | Dog | Int? value) => {
+  let description = match value {
+    Cat => value.name
+    Dog => "Dog of " + value.owner
+    null => "Nothing"
+    1 => "One"
+    else => "Number"
+  }
+  description
+}
+describe(Cat("Tom"))
+describe(Dog("Jon"))
+describe(null)
+describe(1)
+describe(2)
+match false {
+  true => "yes"
+  false => "no"
+}`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Tom"),
+		interpreter.StringValue("Dog of Jon"),
+		interpreter.StringValue("Nothing"),
+		interpreter.StringValue("One"),
+		interpreter.StringValue("Number"),
+		interpreter.StringValue("no"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect match output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestIfExpressionWithoutElse(t *testing.T) {
+	code := matchStructs + `let pet: Cat | Dog = Dog("Jon")
+let name = if pet is Cat => pet.name else if pet is Dog => pet.owner
+name`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Jon"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect if expression output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestUnmatchedMatchThrows(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok || !strings.Contains(thrown.Error(), "MatchError: No arm of the match matched 5 of type Int") {
+			t.Errorf("Expected a match error, got %v", r)
+		}
+	}()
+	base.Execute(nil, `match 5 {
+  1 => "one"
+}`, false)
+}
+
+func TestTyperReportsMissingMatchCases(t *testing.T) {
+	code := matchStructs + `let pet: Cat | Dog | Int? = 3
+let name = match pet {
+  Cat => pet.name
+  null => "Nothing"
+}
+let flag = true
+let word = match flag {
+  true => "yes"
+}
+let exhaustive = match pet {
+  Cat => pet.name
+  Dog => pet.owner
+  Int => "Number"
+  null => "Nothing"
+}`
+	errors, warnings := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors,
+		"Match on Cat | Dog | Int? is missing cases: Dog, Int",
+		"Match on Boolean is missing cases: false")
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings but got %v", warnings)
+	}
+}
+
+func TestTyperReportsMissingIfCases(t *testing.T) {
+	code := matchStructs + `let pet: Cat | Dog | Int = 3
+let name = if pet is Cat => pet.name else if pet is Dog => pet.owner
+let exhaustive = if pet is Cat => pet.name else if pet is Dog => pet.owner else if pet is Int => "Number"
+let flag = true
+let word = if flag => "yes"`
+	errors, _ := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors,
+		"if expression checking the type of pet is missing cases: Int",
+		"An if expression without an else branch must check the type of a variable with is")
+}
+
+func TestTyperReportsUnreachableArms(t *testing.T) {
+	code := matchStructs + `let pet: Cat | Dog = Cat("Tom")
+let name = match pet {
+  Cat => pet.name
+  Dog => pet.owner
+  Cat => "Again"
+  else => "Never"
+}
+let owner = if pet is Dog => pet.owner else if pet is Dog => "Again" else => "Cat"
+let described = if pet is Cat => pet.name else if pet is Dog => pet.owner else => "Never"`
+	errors, warnings := typeCheckWithWarnings(t, code)
+	expectTypeErrors(t, errors)
+	expected := []string{
+		"Unreachable match arm",
+		"Unreachable match arm",
+		"Unreachable branch, as pet of type Cat can never be Dog here",
+		"Unreachable else branch, as every case of pet has already been checked",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings but got %v", len(expected), warnings)
+	}
+	for i, message := range expected {
+		if !strings.Contains(warnings[i].Message, message) {
+			t.Errorf("Expected warning containing %q but got %q", message, warnings[i].Message)
+		}
+	}
+	if warnings[0].Position.String() != "10:2" {
+		t.Errorf("Expected the first unreachable arm to be reported at 10:2 but was %s", warnings[0].Position.String())
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+const narrowingStructs = `struct Person {
+  String name
+  Int age
+}
+struct Dog {
+  String owner
+}
+`
+
+func TestNarrowedPropertyAccess(t *testing.T) {
+	code := narrowingStructs + `let p: Any = Person("Alice", 30)
+let q: Person? = Person("Bob", 40)
+let r: Person | Dog = Dog("Carol")
+let n: Person? = null
+if p is Person {
+  p.name
+}
+if q != null && q.age == 40 {
+  q.name
+}
+if r !is Person {
+  r.owner
+} else {
+  r.name
+}
+n == null || n.age == 1`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Alice"),
+		interpreter.StringValue("Bob"),
+		interpreter.StringValue("Carol"),
+		interpreter.BooleanValue(true),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect narrowing output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestLogicalOperatorsShortCircuit(t *testing.T) {
+	code := `let mut evaluated = false
+let check() => {
+  evaluated = true
+  true
+}
+false && check()
+true || check()
+evaluated
+!evaluated && check()
+evaluated`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.BooleanValue(false),
+		interpreter.BooleanValue(true),
+		interpreter.BooleanValue(false),
+		interpreter.BooleanValue(true),
+		interpreter.BooleanValue(true),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect logical operator output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestTyperNarrowsAfterTypeCheck(t *testing.T) {
+	code := narrowingStructs + `let greet(String name) => "Hello " + name
+let p: Any = Person("Alice", 30)
+if p is Person {
+  greet(p.age)
+}
+greet(p.age)`
+	expectTypeErrors(t, typeCheck(t, code), "Expected [Char] for parameter name of greet and got Int")
+}
+
+func TestTyperNarrowsElseBranch(t *testing.T) {
+	code := narrowingStructs + `let greet(String name) => "Hello " + name
+let describe(Person | Dog animal) => {
+  let description = if animal !is Person => greet(animal.owner) else => greet(animal.age)
+  description
+}`
+	expectTypeErrors(t, typeCheck(t, code), "Expected [Char] for parameter name of greet and got Int")
+}
+
+func TestTyperNarrowsNullChecks(t *testing.T) {
+	code := narrowingStructs + `let q: Person? = null
+if q != null && q.age == 40 {
+  q.name
+}
+if q == null || q.name == "Bob" {
+  q?.name
+}
+q.name`
+	errors := typeCheck(t, code)
+	expectTypeErrors(t, errors, "Cannot access property name of Person?, which may be null")
+	if errors[0].Position.String() != "14:1" {
+		t.Errorf("Expected the error to be reported at 14:1 but was %s", errors[0].Position.String())
+	}
+}
+
+func TestTyperNarrowingAllowsReassignment(t *testing.T) {
+	code := `let mut value: Int | String = 1
+if value is Int {
+  value = "one"
+  value = 2
+}`
+	expectTypeErrors(t, typeCheck(t, code))
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestMapMissingKeyIsNull(t *testing.T) {
+	code := `let map = {"a": 1}
+let value: Int? = map["b"]
+value == null`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.BooleanValue(true),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect missing map key output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestElvisOperator(t *testing.T) {
+	code := `let map = {"a": 1}
+map["a"] ?: 0
+map["b"] ?: 0`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(1),
+		interpreter.IntValue(0),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect elvis output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestSafeNavigation(t *testing.T) {
+	code := `struct Person {
+    String name
+}
+let present: Person? = Person("Bob")
+let absent: Person? = null
+present?.name
+absent?.name
+absent?.name ?: "Nobody"`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Bob"),
+		interpreter.NullValue(),
+		interpreter.StringValue("Nobody"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect safe navigation output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestNullAssignedToNonOptionalType(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows null to be assigned to a non-optional type")
+		}
+	}()
+
+	code := `let x: Int = null`
+	base.Execute(nil, code, false)
+}
+
+func TestNotNullAssertion(t *testing.T) {
+	code := `let x: Int? = 3
+x!!`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(3),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect non-null assertion output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestNotNullAssertionOnNull(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Non-null assertion on null did not fail")
+		}
+	}()
+
+	code := `let x: Int? = null
+x!!`
+	base.Execute(nil, code, false)
+}
+
+func TestCollectionIndexOutOfBounds(t *testing.T) {
+	code := `let numbers = [1, 2]
+let read(Int index) => {
+    try {
+        return numbers[index]
+    } catch (e: IndexError) {
+        return e.message
+    }
+}
+read(1)
+read(5)
+read(0 - 1)`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.IntValue(2),
+		interpreter.StringValue("Index 5 is out of bounds for a collection of length 2"),
+		interpreter.StringValue("Index -1 is out of bounds for a collection of length 2"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect out of bounds output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
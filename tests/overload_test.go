@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMostSpecificOverload(t *testing.T) {
+	code := `let describe(Any x) => "any"
+let describe(Int x) => "int"
+let describe(String x) => "string"
+describe(3)
+describe("a")
+describe(true)
+"a" + "b"`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("int"),
+		interpreter.StringValue("string"),
+		interpreter.StringValue("any"),
+		interpreter.StringValue("ab"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect overload resolution output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestAmbiguousOverloadCall(t *testing.T) {
+	defer func() {
+		r := recover()
+		message, ok := r.(string)
+		if !ok || !strings.Contains(message, "g(Any, Int) => Any") || !strings.Contains(message, "g(Int, Any) => Any") {
+			t.Errorf("Expected an ambiguity error listing both candidates, got %v", r)
+		}
+	}()
+
+	code := `let g(Int a, Any b) => 1
+let g(Any a, Int b) => 2
+g(1, 2)`
+	base.Execute(nil, code, false)
+}
+
+func TestAmbiguousAnonymousOverloadCall(t *testing.T) {
+	defer func() {
+		r := recover()
+		message, ok := r.(string)
+		if !ok || !strings.Contains(message, "Ambiguous call to <anonymous>") {
+			t.Errorf("Expected an ambiguity error for the anonymous candidates, got %v", r)
+		}
+	}()
+
+	code := `let first() => (Int a, Any b) => 1
+let second() => (Any a, Int b) => 2
+let g = first()
+let g = second()
+g(1, 2)`
+	base.Execute(nil, code, false)
+}
+
+func TestDuplicateOverloadDefinition(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows two overloads with the same signature")
+		}
+	}()
+
+	code := `let f(Int a) => 1
+let f(Int b) => 2`
+	base.Execute(nil, code, false)
+}
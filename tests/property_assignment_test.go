@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestMutablePropertyAssignment(t *testing.T) {
+	code := `struct Person {
+    String name
+    mut Int age
+}
+let bob = Person("Bob", 30)
+bob.age = 31
+bob.age += 2
+bob.age`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(33),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect property assignment output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestImmutablePropertyAssignment(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows reassignment of immutable properties")
+		}
+	}()
+
+	code := `struct Person {
+    String name
+}
+let bob = Person("Bob")
+bob.name = "Rob"`
+	base.Execute(nil, code, false)
+}
+
+func TestPropertyAssignmentWithInvalidType(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows property assignment of incorrect types")
+		}
+	}()
+
+	code := `struct Person {
+    mut Int age
+}
+let bob = Person(30)
+bob.age = "old"`
+	base.Execute(nil, code, false)
+}
+
+func TestImmutablePropertyAssignmentIsCatchable(t *testing.T) {
+	code := `struct Person {
+    String name
+}
+let bob = Person("Bob")
+let rename() => {
+    try {
+        bob.name = "Rob"
+    } catch (e: TypeError) {
+        return e.message
+    }
+    "renamed"
+}
+rename()
+bob.name`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("Cannot reassign immutable property name of type Person"),
+		interpreter.StringValue("Bob"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect immutable property output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
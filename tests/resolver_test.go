@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"strings"
+	"testing"
+)
+
+func TestResolvedVariables(t *testing.T) {
+	code := `let x = 1
+let addX(Int y) => x + y
+let mut counter = 0
+let increment() => {
+    counter = counter + 1
+    counter
+}
+let adder(Int a) => (Int b) => a + b
+let first() => second()
+let second() => 2
+increment()
+increment()
+addX(2)
+adder(2)(5)
+first()`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expected := "[<nil>, <nil>, <nil>, <nil>, <nil>, <nil>, <nil>, 1, 2, 3, 7, 2]"
+
+	if formatValues(results) != expected {
+		t.Errorf("Incorrect resolved variable output, got %v but expected %v", formatValues(results), expected)
+	}
+}
+
+func TestResolvedParametersShadowVariables(t *testing.T) {
+	code := `let value = "outer"
+let mut log = ""
+let echo(String value) => value
+let describe(String message) => {
+    try {
+        throw message
+    } catch (e) {
+        log = log + e.message
+        return echo(e.message)
+    }
+}
+describe("inner")
+log`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expected := "[<nil>, <nil>, <nil>, <nil>, inner, inner]"
+
+	if formatValues(results) != expected {
+		t.Errorf("Incorrect shadowing output, got %v but expected %v", formatValues(results), expected)
+	}
+}
+
+func TestReceiverMembersAreNotResolved(t *testing.T) {
+	code := `let name = "global"
+struct Person {
+    String name
+}
+extend Person {
+    let greet() => "Hello " + name
+    let rename(String newName) => {
+        let greeting = "Hi " + newName
+        greeting
+    }
+}
+Person("Bob").greet()
+Person("Bob").rename("Rob")`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expected := "[<nil>, <nil>, <nil>, Hello Bob, Hi Rob]"
+
+	if formatValues(results) != expected {
+		t.Errorf("Incorrect extension output, got %v but expected %v", formatValues(results), expected)
+	}
+}
+
+func TestLocalVariablesAreStoredInSlots(t *testing.T) {
+	code := `let make(Int n) => {
+    let get() => n
+    get()
+}
+make(1)
+make(2)
+let later() => {
+    let read() => value + other()
+    let other() => 2
+    let value = 1
+    read()
+}
+later()
+let pick(Int x) => "global int"
+let pick(String x) => "global string"
+let overloads() => {
+    let show(Int x) => "int"
+    let show(String x) => "string"
+    show(1) + " " + show("a")
+}
+let shadow() => {
+    let pick(Any x) => "local"
+    pick(1) + " " + pick("a")
+}
+overloads()
+shadow()`
+	expectedResults := []*interpreter.Value{
+		nil,
+		interpreter.IntValue(1),
+		interpreter.IntValue(2),
+		nil,
+		interpreter.IntValue(3),
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("int string"),
+		interpreter.StringValue("local local"),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestLocalVariablesReadBeforeInitialisation(t *testing.T) {
+	code := `let early() => {
+    let read() => value
+    let result = read()
+    let value = 1
+    result
+}
+early()`
+	for _, options := range [][]interpreter.Option{nil, {interpreter.WithBytecode()}} {
+		err := fmt.Sprint(recovered(code, options...))
+		if !strings.Contains(err, "Variable value was read before it was initialised") {
+			t.Errorf("Expected value to be uninitialised, got %s", err)
+		}
+	}
+}
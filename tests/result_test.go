@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"testing"
+)
+
+func TestResultPropagation(t *testing.T) {
+	code := `let parse(String s) => Result<Int, String> {
+    if s == "1" {
+        return Ok(1)
+    }
+    return Err("bad input")
+}
+let addOne(String s) => Result<Int, String> {
+    let n = parse(s)?
+    Ok(n + 1)
+}
+addOne("1")
+addOne("x")`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.OkValue(interpreter.IntValue(2)),
+		interpreter.ErrValue(interpreter.StringValue("bad input")),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect result propagation output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestResultExtensions(t *testing.T) {
+	code := `let ok: Result<Int, String> = Ok(3)
+let err: Result<Int, String> = Err("failed")
+ok.map((Int x) => x * 10)
+err.map((Int x) => x * 10)
+ok.flatMap((Int x) => Err("rejected"))
+ok.getOrElse(0)
+err.getOrElse(0)`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.OkValue(interpreter.IntValue(30)),
+		interpreter.ErrValue(interpreter.StringValue("failed")),
+		interpreter.ErrValue(interpreter.StringValue("rejected")),
+		interpreter.IntValue(3),
+		interpreter.IntValue(0),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect result extension output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestResultWithMismatchedValueType(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows Ok(String) to be assigned to Result<Int, String>")
+		}
+	}()
+
+	code := `let r: Result<Int, String> = Ok("Hello")`
+	base.Execute(nil, code, false)
+}
+
+func TestResultPropagationFromEveryExpression(t *testing.T) {
+	code := `let fail() => Result<Int, String> {
+    return Err("bad")
+}
+let isOne(Int n) => n == 1
+let inWhile() => Result<Int, String> {
+    while fail()? == 1 {
+    }
+    Ok(0)
+}
+let inIf() => Result<Int, String> {
+    if fail()? == 1 {
+        return Ok(2)
+    }
+    Ok(1)
+}
+let inMap() => Result<Int, String> {
+    let m = {"a": fail()?}
+    Ok(1)
+}
+let inCollection() => Result<Int, String> {
+    let c = [1, fail()?]
+    Ok(1)
+}
+let inAnd() => Result<Int, String> {
+    let b = true && fail()? == 1
+    Ok(1)
+}
+let inOr() => Result<Int, String> {
+    let b = false || fail()? == 1
+    Ok(1)
+}
+let inNot() => Result<Int, String> {
+    let b = !isOne(fail()?)
+    Ok(1)
+}
+let inElvis() => Result<Int, String> {
+    let x = fail()? ?: 2
+    Ok(1)
+}
+let inTypeCheck() => Result<Int, String> {
+    let b = fail()? is Int
+    Ok(1)
+}
+let inAccess() => Result<Int, String> {
+    let x = [1, 2][fail()?]
+    Ok(1)
+}
+inWhile()
+inIf()
+inMap()
+inCollection()
+inAnd()
+inOr()
+inNot()
+inElvis()
+inTypeCheck()
+inAccess()`
+	expectedResults := make([]*interpreter.Value, 12)
+	for i := 0; i < 10; i++ {
+		expectedResults = append(expectedResults, interpreter.ErrValue(interpreter.StringValue("bad")))
+	}
+	expectBothEngines(t, code, expectedResults)
+}
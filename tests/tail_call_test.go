@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tailCallCode = `let count(Int n, Int acc) => Int {
+    if n == 0 {
+        return acc
+    }
+    return count(n - 1, acc + 1)
+}
+let sum(Int n, Int acc) => Int {
+    if n == 0 {
+        return acc
+    }
+    sum(n - 1, acc + n)
+}
+let isEven(Int n) => Boolean {
+    if n == 0 {
+        return true
+    }
+    isOdd(n - 1)
+}
+let isOdd(Int n) => Boolean {
+    if n == 0 {
+        return false
+    }
+    return isEven(n - 1)
+}
+let fail(Int n) => Int {
+    if n == 0 {
+        throw "bottom"
+    }
+    fail(n - 1)
+}
+count(100000, 0)
+sum(1000, 0)
+isEven(10001)
+isOdd(10001)
+try {
+    fail(1000)
+} catch (e) {
+    e.stackTrace.size
+}`
+
+func TestTailCalls(t *testing.T) {
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(100000),
+		interpreter.IntValue(500500),
+		interpreter.BooleanValue(false),
+		interpreter.BooleanValue(true),
+		interpreter.IntValue(1), //The frames of the tail calls are reused, so only one is left
+	}
+	walked, compiled := executeBoth(tailCallCode)
+	if !reflect.DeepEqual(walked, expectedResults) {
+		t.Errorf("Incorrect tail call output, got %s but expected %s", formatValues(walked), formatValues(expectedResults))
+	}
+	if !reflect.DeepEqual(compiled, expectedResults) {
+		t.Errorf("Incorrect bytecode tail call output, got %s but expected %s", formatValues(compiled), formatValues(expectedResults))
+	}
+}
+
+func TestTailCallsCheckReturnTypes(t *testing.T) {
+	defer func() {
+		r := recover()
+		thrown, ok := r.(*interpreter.Thrown)
+		if !ok {
+			t.Fatalf("Expected a type error, got %v", r)
+		}
+		message := thrown.Value.Value.(*interpreter.Instance).Values["message"].String()
+		if !strings.Contains(message, "Function 'number' did not return value of type Int, instead was [Char]") {
+			t.Errorf("Incorrect error for tail call returning the wrong type, got %s", message)
+		}
+	}()
+	code := `let text() => "a"
+let number() => Int {
+    return text()
+}
+number()`
+	base.Execute(nil, code, false)
+}
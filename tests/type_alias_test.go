@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRecursiveTypeAlias(t *testing.T) {
+	code := `type Nested = Int | [Nested]
+let n: Nested = [1, [2, [3]]]
+n[0]`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.IntValue(1),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect recursive type alias output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestMutuallyRecursiveTypeAliases(t *testing.T) {
+	code := `type Even = Int | [Odd]
+type Odd = String | [Even]
+let e: Even = [[1]]
+let o: Odd = [2]`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect mutually recursive type alias output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestMutuallyRecursiveTypeAliasRejection(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows a value that does not match a recursive type alias")
+		}
+	}()
+
+	code := `type Even = Int | [Odd]
+type Odd = String | [Even]
+let e: Even = [1]`
+	base.Execute(nil, code, false)
+}
+
+func TestEquivalentRecursiveTypeAliases(t *testing.T) {
+	code := `type First = Int | [First]
+type Second = Int | [Second]
+let f: (First) => Int = (Second x) => Int {
+    return 1
+}
+f([[2]])`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(1),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect recursive type alias comparison output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestRecursiveTypeAliasesCheckedConcurrently(t *testing.T) {
+	code := `type First = Int | [First]
+type Second = Int | [Second]
+let f: (First) => Int = (Second x) => Int {
+    return 1
+}
+f([[2]])`
+	var wg sync.WaitGroup
+	results := make([][]*interpreter.Value, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _, _ = base.Execute(nil, code, false)
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		if len(result) != 4 || !reflect.DeepEqual(result[3], interpreter.IntValue(1)) {
+			t.Errorf("Incorrect concurrent recursive type alias output, got %v", formatValues(result))
+		}
+	}
+}
+
+func TestParameterisedTypeAlias(t *testing.T) {
+	code := `type Pair<A, B> = { A first, B second }
+struct Entry {
+    Int first
+    String second
+}
+let p: Pair<Int, String> = Entry(1, "a")
+p.second`
+	results, _, _, _ := base.Execute(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		interpreter.StringValue("a"),
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("Incorrect parameterised type alias output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
+	}
+}
+
+func TestParameterisedTypeAliasRejection(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Interpreter allows a value that does not match an instantiated type alias")
+		}
+	}()
+
+	code := `type Pair<A, B> = { A first, B second }
+struct Entry {
+    Int first
+    String second
+}
+let p: Pair<String, String> = Entry(1, "a")`
+	base.Execute(nil, code, false)
+}
+
+func TestRecursiveStructuralTypeAlias(t *testing.T) {
+	tree := `type Tree = { Int value, Tree? left, Tree? right }
+type Branch = Node?
+struct Node {
+    Int value
+    Branch left
+    Branch right
+}
+struct Label {
+    String value
+    Branch left
+    Branch right
+}
+let sum(Tree? tree) => Int {
+    if tree == null {
+        return 0
+    }
+    return tree!!.value + sum(tree!!.left) + sum(tree!!.right)
+}
+`
+	code := tree + `let tree: Tree = Node(1, Node(2, null, null), Node(3, Node(4, null, null), null))
+sum(tree)`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(10),
+	}
+	expectBothEngines(t, code, expectedResults)
+
+	if recovered(tree+`let label: Tree = Label("root", null, null)`) == nil {
+		t.Errorf("Interpreter allows a struct with a String value to be used as a Tree")
+	}
+}
+
+func TestNamedPropertyTypeAlias(t *testing.T) {
+	aliases := `type Tree = { value: Int, children: [Tree] }
+type Forest = {
+    value: Int,
+    children: [Forest]
+}
+type Labels = { value: String, children: [Labels] }
+let count({String: Int} counts) => counts["trees"]
+`
+	code := aliases + `let f: (Tree) => Int = (Forest x) => Int {
+    return 1
+}
+count({"trees": 2})`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.IntValue(2),
+	}
+	expectBothEngines(t, code, expectedResults)
+	expectTypeErrors(t, typeCheck(t, code))
+
+	if recovered(aliases+`let h: (Tree) => Int = (Labels x) => Int {
+    return 1
+}`) == nil {
+		t.Errorf("Interpreter allows a Labels to be used as a Tree")
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/base"
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"github.com/ElaraLang/elara/typer"
+	"reflect"
+	"testing"
+)
+
+func inferTypes(t *testing.T, code string) (*typer.Typer, []typer.TypeError) {
+	stmts, errs := parserlegacy.NewParser(lexer.Lex(code)).Parse()
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected syntax errors %v", errs)
+	}
+	checker := typer.NewTyper(stmts)
+	return checker, checker.HandleTyping()
+}
+
+func TestTyperInfersPrincipalTypes(t *testing.T) {
+	code := `let id(x) => x
+let constant(a, b) => a
+let apply(f, x) => f(x)
+let compose(f, g) => (x) => f(g(x))
+let double(x) => x * 2
+let greet(name) => "Hello " + name
+let factorial(n) => {
+    return if n == 0 => 1 else => n * factorial(n - 1)
+}
+let choose(condition, a, b) => {
+    return if condition => a else => b
+}
+let capture(x) => {
+    let inner() => x
+    inner()
+}`
+	checker, errors := inferTypes(t, code)
+	expectTypeErrors(t, errors)
+	expected := map[string]string{
+		"id":        "<T> (T) => T",
+		"constant":  "<T, U> (T, U) => T",
+		"apply":     "<T, U> ((T) => U, T) => U",
+		"compose":   "<T, U, V> ((T) => U, (V) => T) => (V) => U",
+		"double":    "(Int) => Int",
+		"greet":     "([Char]) => [Char]",
+		"factorial": "(Int) => Int",
+		"choose":    "<T> (Boolean, T, T) => T",
+		"capture":   "<T> (T) => T",
+	}
+	for name, principal := range expected {
+		inferred := checker.TypeOfVariable(name)
+		if inferred == nil {
+			t.Errorf("Expected %s to have type %s but it was not inferred", name, principal)
+			continue
+		}
+		if inferred.Name() != principal {
+			t.Errorf("Expected %s to have type %s but got %s", name, principal, inferred.Name())
+		}
+	}
+}
+
+func TestTyperInstantiatesGenericsAtCallSites(t *testing.T) {
+	code := `<T> let first([T] values) => values[0]
+let id(x) => x
+let choose(condition, a, b) => {
+    return if condition => a else => b
+}
+let number: Int = id(1)
+let text: String = id("a")
+let wrong: String = id(1)
+let mixed = choose(true, 1, "a")
+let element: Int = first([1, 2])
+let wrongElement: String = first([1, 2])
+let doubled: Int = id((x) => x * 2)(3)`
+	_, errors := inferTypes(t, code)
+	expectTypeErrors(t, errors,
+		"Cannot use value of type Int in place of [Char] for variable wrong",
+		"Expected Int for parameter b of choose (<T> (Boolean, T, T) => T) and got [Char]",
+		"Cannot use value of type Int in place of [Char] for variable wrongElement")
+}
+
+func TestTyperChecksContractsOfInstantiatedGenerics(t *testing.T) {
+	code := `<T: Int | String> let f(T x) => x
+let anything(Any x) => f(x)
+f(1)
+f("a")
+f(1.5)`
+	_, errors := inferTypes(t, code)
+	expectTypeErrors(t, errors,
+		"Type Float does not fulfill the contract Int | [Char] of type parameter T of f (<T: Int | [Char]> (T) => T)")
+}
+
+func TestTyperReportsConflictingUses(t *testing.T) {
+	code := `let square(Int x) => x * x
+let greet(String name) => "Hello " + name
+let both(x) => square(x) + greet(x)
+let run(f) => f(1) + f("a")`
+	_, errors := inferTypes(t, code)
+	expectTypeErrors(t, errors,
+		"Expected [Char] for parameter name of greet and got Int",
+		"Expected Int for parameter 1 of f and got [Char]")
+}
+
+func TestInferredGenericFunctionsRun(t *testing.T) {
+	code := `let id(x) => x
+let apply(f, x) => f(x)
+id(1)
+id("a")
+apply((n) => n + 1, 2)`
+	results, _, _, _ := base.ExecuteChecked(nil, code, false)
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.IntValue(1),
+		interpreter.StringValue("a"),
+		interpreter.IntValue(3),
+	}
+	if !reflect.DeepEqual(expectedResults, results) {
+		t.Errorf("Incorrect parsing output, expected %s but got %s", formatValues(expectedResults), formatValues(results))
+	}
+}
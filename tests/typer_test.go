@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"github.com/ElaraLang/elara/typer"
+	"strings"
+	"testing"
+)
+
+func typeCheck(t *testing.T, code string) []typer.TypeError {
+	psr := parserlegacy.NewParser(lexer.Lex(code))
+	stmts, errs := psr.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected syntax errors %v", errs)
+	}
+	return typer.NewTyper(stmts).HandleTyping()
+}
+
+func expectTypeErrors(t *testing.T, errors []typer.TypeError, expected ...string) {
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d type errors but got %v", len(expected), errors)
+	}
+	for i, message := range expected {
+		if !strings.Contains(errors[i].Message, message) {
+			t.Errorf("Expected type error containing %q but got %q", message, errors[i].Message)
+		}
+	}
+}
+
+func TestTyperAcceptsValidProgram(t *testing.T) {
+	code := `struct Person {
+  String name
+  Int age
+}
+let older(Person p, Int years) => Person {
+  return Person(p.name, p.age + years)
+}
+let mut total = 0
+let alice = Person("Alice", 30)
+total = older(alice, 5).age
+let double = (Int x) => x * 2
+double(total)
+if total == 35 {
+  total = total + 1
+}`
+	expectTypeErrors(t, typeCheck(t, code))
+}
+
+func TestTyperReportsWrongArgument(t *testing.T) {
+	code := `let square(Int x) => x * x
+square("4")`
+	errors := typeCheck(t, code)
+	expectTypeErrors(t, errors, "Expected Int for parameter x of square and got [Char]")
+	if errors[0].Position != lexer.CreatePosition(1, 6) {
+		t.Errorf("Expected the error to be reported at 1:6 but was %s", errors[0].Position.String())
+	}
+}
+
+func TestTyperReportsWrongArity(t *testing.T) {
+	code := `let add(Int a, Int b) => a + b
+add(1)`
+	expectTypeErrors(t, typeCheck(t, code), "Illegal number of arguments for function add. Expected 2, received 1")
+}
+
+func TestTyperReportsWrongReturn(t *testing.T) {
+	code := `let name() => Int {
+  return "Elara"
+}`
+	expectTypeErrors(t, typeCheck(t, code), "Function 'name' did not return value of type Int, instead was [Char]")
+}
+
+func TestTyperReportsBadAssignments(t *testing.T) {
+	code := `let mut count = 0
+count = "many"
+let fixed = 1
+fixed = 2`
+	expectTypeErrors(t, typeCheck(t, code), "count", "Cannot reassign immutable variable")
+}
+
+func TestTyperReportsEveryError(t *testing.T) {
+	code := `let x: Int = "one"
+let y: String = 2
+if 3 {
+  x
+}`
+	expectTypeErrors(t, typeCheck(t, code),
+		"Cannot use value of type [Char] in place of Int for variable x",
+		"Cannot use value of type Int in place of [Char] for variable y",
+		"Condition must be a Boolean, but was Int")
+}
+
+func TestTyperIgnoresRuntimeTypes(t *testing.T) {
+	code := `let describe(Any value) => value
+let wrapped: Any = 3
+describe(wrapped)
+describe(1)
+describe("one")`
+	expectTypeErrors(t, typeCheck(t, code))
+}
+
+func TestTyperDoesNotEvaluateDefaultValues(t *testing.T) {
+	code := `struct Config {
+  String name
+  Int retries = 1 / 0
+}
+let config = Config("main")
+config.retries + 1`
+	expectTypeErrors(t, typeCheck(t, code))
+}
+
+func TestTyperReportsWrongDefaultValues(t *testing.T) {
+	code := `struct Config {
+  Int retries = "three"
+}`
+	expectTypeErrors(t, typeCheck(t, code), "Cannot use value of type [Char] in place of Int for property retries")
+}
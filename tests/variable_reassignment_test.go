@@ -70,3 +70,54 @@ func TestSimpleVariableReassignmentWithImmutableVariable(t *testing.T) {
 		t.Errorf("Incorrect parsing output, got %v but expected %v", formatValues(results), formatValues(expectedResults))
 	}
 }
+
+func TestImmutableVariableReassignmentIsCatchable(t *testing.T) {
+	code := `let a = 3
+let attempt() => {
+    try {
+        a = 4
+    } catch (e: TypeError) {
+        return e.message
+    }
+    "reassigned"
+}
+attempt()
+a`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		interpreter.StringValue("Cannot reassign immutable variable a"),
+		interpreter.IntValue(3),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
+
+func TestAssignmentsEvaluateToUnit(t *testing.T) {
+	code := `struct Box {
+    mut Int content
+}
+let box = Box(1)
+let mut a = 3
+let set(Int value) => a = value
+let fill(Int value) => box.content = value
+a = 4
+box.content = 2
+set(5)
+fill(6)
+a
+box.content`
+	expectedResults := []*interpreter.Value{
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		interpreter.UnitValue(),
+		interpreter.UnitValue(),
+		interpreter.IntValue(5),
+		interpreter.IntValue(6),
+	}
+	expectBothEngines(t, code, expectedResults)
+}
@@ -0,0 +1,26 @@
+package typer
+
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/lexer"
+)
+
+//TypeError is a mistake in a program that was found without running it
+type TypeError struct {
+	Position lexer.Position
+	Message  string
+}
+
+func (e TypeError) Error() string {
+	return fmt.Sprintf("Type Error: %s at %s", e.Message, e.Position.String())
+}
+
+//TypeWarning is something in a program that is allowed, but may not behave as expected
+type TypeWarning struct {
+	Position lexer.Position
+	Message  string
+}
+
+func (w TypeWarning) String() string {
+	return fmt.Sprintf("Warning: %s at %s", w.Message, w.Position.String())
+}
@@ -0,0 +1,162 @@
+package typer
+
+import (
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"strings"
+)
+
+/*
+checkMatch checks every arm of a match, returning the type of its result.
+If the type of the subject is known, arms that can never match are warned about,
+and a match without an else arm must have an arm for every case of the subject.
+*/
+func (t *Typer) checkMatch(e parserlegacy.MatchExpr) interpreter.Type {
+	subject := t.typeOf(e.Subject)
+	subjectVariable, isVariable := e.Subject.(parserlegacy.VariableExpr)
+
+	var remaining interpreter.Type //The cases not yet matched, or nil if they are not known
+	if known(subject) {
+		remaining = subject
+	}
+	matchedBooleans := map[bool]bool{}
+	hasElse := false
+	results := make([]interpreter.Type, len(e.Arms))
+	for i, arm := range e.Arms {
+		var narrowed interpreter.Type
+		reachable := true
+		switch {
+		case arm.Type != nil:
+			narrowed = t.resolveType(arm.Type)
+			if remaining != nil && narrowed != nil {
+				reachable = overlaps(remaining, narrowed, t.ctx)
+				remaining = without(remaining, narrowed, t.ctx)
+			} else {
+				remaining = nil
+			}
+		case arm.Value != nil:
+			narrowed = t.typeOf(arm.Value)
+			if remaining == nil {
+				break
+			}
+			reachable = overlaps(remaining, narrowed, t.ctx)
+			if boolean, isBoolean := arm.Value.(parserlegacy.BooleanLiteralExpr); isBoolean {
+				reachable = reachable && !matchedBooleans[boolean.Value]
+				matchedBooleans[boolean.Value] = true
+				if matchedBooleans[true] && matchedBooleans[false] {
+					remaining = without(remaining, interpreter.BooleanType, t.ctx)
+				}
+			} else if narrowed == interpreter.NullType {
+				remaining = without(remaining, interpreter.NullType, t.ctx)
+			}
+		default:
+			hasElse = true
+			narrowed = remaining
+			if remaining != nil {
+				reachable = len(cases(remaining)) != 0
+				remaining = interpreter.NothingType
+			}
+		}
+		if !reachable {
+			t.warn(arm.Position, "Unreachable match arm, as every value it matches is matched by an earlier arm")
+		}
+
+		narrowing := narrowing{}
+		if isVariable && narrowed != nil {
+			narrowing[subjectVariable.Identifier] = narrowed
+		}
+		t.withNarrowing(narrowing, func() {
+			results[i] = t.typeOf(arm.Result)
+		})
+	}
+
+	if remaining != nil && !hasElse {
+		if missing := caseNames(remaining, matchedBooleans); len(missing) != 0 {
+			t.report(e.Position, "Match on "+subject.Name()+" is missing cases: "+strings.Join(missing, ", "))
+		}
+	}
+	return t.join(results)
+}
+
+/*
+checkTypeCheckBranch checks an if expression whose condition is an `is` check of a variable, as part of an if / else if chain.
+A branch checking for a type that the variable can no longer be is warned about, and if there is no else branch,
+the chain must have a branch for every case of the variable.
+*/
+func (t *Typer) checkTypeCheckBranch(e parserlegacy.IfElseExpr) {
+	check, isTypeCheck := e.Condition.(parserlegacy.TypeCheckExpr)
+	var name string
+	var checked *variable
+	var checkedType interpreter.Type
+	if isTypeCheck {
+		if variableExpr, isVariable := check.Expr.(parserlegacy.VariableExpr); isVariable {
+			name = variableExpr.Identifier
+			checked = t.findVariable(name)
+			checkedType = t.resolveType(check.Type)
+		}
+	}
+	if checked == nil {
+		if e.ElseResult == nil {
+			t.report(e.Position, "An if expression without an else branch must check the type of a variable with is")
+		}
+		return
+	}
+	if !known(checked.Type) || checkedType == nil {
+		return
+	}
+	if !overlaps(checked.Type, checkedType, t.ctx) {
+		t.warn(e.Position, "Unreachable branch, as "+name+" of type "+checked.Type.Name()+" can never be "+checkedType.Name()+" here")
+	}
+	remaining := without(checked.Type, checkedType, t.ctx)
+	if e.ElseResult == nil {
+		if missing := caseNames(remaining, nil); len(missing) != 0 {
+			t.report(e.Position, "if expression checking the type of "+name+" is missing cases: "+strings.Join(missing, ", "))
+		}
+	} else if _, isElseIf := e.ElseResult.(parserlegacy.IfElseExpr); !isElseIf && len(cases(remaining)) == 0 {
+		t.warn(e.Position, "Unreachable else branch, as every case of "+name+" has already been checked")
+	}
+}
+
+//cases splits a type into the types of values it could be, such as the members of a union
+func cases(t interpreter.Type) []interpreter.Type {
+	switch c := t.(type) {
+	case *interpreter.UnionType:
+		members := make([]interpreter.Type, 0)
+		for _, member := range c.Members() {
+			members = append(members, cases(member)...)
+		}
+		return members
+	case *interpreter.OptionalType:
+		return append(cases(c.ElementType), interpreter.NullType)
+	}
+	if t == interpreter.NothingType {
+		return nil
+	}
+	return []interpreter.Type{t}
+}
+
+//caseNames names the cases of a type, naming the unmatched value of a Boolean if the other has been matched
+func caseNames(t interpreter.Type, matchedBooleans map[bool]bool) []string {
+	remaining := cases(t)
+	names := make([]string, len(remaining))
+	for i, remainingCase := range remaining {
+		names[i] = remainingCase.Name()
+		if remainingCase == interpreter.BooleanType && matchedBooleans[true] != matchedBooleans[false] {
+			names[i] = "true"
+			if matchedBooleans[true] {
+				names[i] = "false"
+			}
+		}
+	}
+	return names
+}
+
+//overlaps returns true if some value could be of both type a and type b
+func overlaps(a interpreter.Type, b interpreter.Type, ctx *interpreter.Context) bool {
+	for _, aCase := range cases(a) {
+		if b.Accepts(aCase, ctx) || aCase.Accepts(b, ctx) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,683 @@
+package typer
+
+import (
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+	"github.com/ElaraLang/elara/util"
+	"strconv"
+)
+
+//typeOf checks an expression, returning its type or nil if the type can only be known at runtime
+func (t *Typer) typeOf(expr parserlegacy.Expr) interpreter.Type {
+	switch e := expr.(type) {
+	case parserlegacy.IntegerLiteralExpr:
+		return interpreter.IntType
+	case parserlegacy.FloatLiteralExpr:
+		return interpreter.FloatType
+	case parserlegacy.BooleanLiteralExpr:
+		return interpreter.BooleanType
+	case parserlegacy.CharLiteralExpr:
+		return interpreter.CharType
+	case parserlegacy.StringLiteralExpr:
+		return interpreter.StringType
+	case parserlegacy.NullLiteralExpr:
+		return interpreter.NullType
+	case parserlegacy.GroupExpr:
+		return t.typeOf(e.Group)
+
+	case parserlegacy.VariableExpr:
+		if found := t.findVariable(e.Identifier); found != nil {
+			return found.Type
+		}
+		return nil
+
+	case parserlegacy.UnaryExpr:
+		operand := t.typeOf(e.Rhs)
+		if e.Op == lexer.Not {
+			return interpreter.BooleanType
+		}
+		return operand
+
+	case parserlegacy.BinaryExpr:
+		return t.binaryType(e)
+
+	case parserlegacy.TypeCheckExpr:
+		t.typeOf(e.Expr)
+		return interpreter.BooleanType
+	case parserlegacy.TypeCastExpr:
+		t.typeOf(e.Expr)
+		return t.resolveType(e.Type)
+
+	case parserlegacy.CollectionExpr:
+		elements := make([]interpreter.Type, len(e.Elements))
+		for i, element := range e.Elements {
+			elements[i] = t.typeOf(element)
+		}
+		elementType := t.join(elements)
+		if elementType == nil {
+			return nil
+		}
+		return interpreter.NewCollectionTypeOf(elementType)
+
+	case parserlegacy.MapExpr:
+		keys := make([]interpreter.Type, len(e.Entries))
+		values := make([]interpreter.Type, len(e.Entries))
+		for i, entry := range e.Entries {
+			keys[i] = t.typeOf(entry.Key)
+			values[i] = t.typeOf(entry.Value)
+		}
+		keyType := t.join(keys)
+		valueType := t.join(values)
+		if keyType == nil || valueType == nil {
+			return nil
+		}
+		return &interpreter.MapType{KeyType: keyType, ValueType: valueType}
+
+	case parserlegacy.AccessExpr:
+		accessed := t.typeOf(e.Expr)
+		t.typeOf(e.Index)
+		if collection, isCollection := accessed.(*interpreter.CollectionType); isCollection {
+			return collection.ElementType
+		}
+		return nil
+
+	case parserlegacy.NotNullExpr:
+		checked := t.typeOf(e.Expr)
+		if optional, isOptional := checked.(*interpreter.OptionalType); isOptional {
+			return optional.ElementType
+		}
+		return checked
+
+	case parserlegacy.PropagateExpr:
+		t.typeOf(e.Expr)
+		return nil
+
+	case parserlegacy.ContextExpr:
+		return t.propertyType(e)
+
+	case parserlegacy.AssignmentExpr:
+		t.checkAssignment(e)
+		return interpreter.UnitType
+
+	case parserlegacy.IfElseExpr:
+		t.checkCondition(e.Condition, e.Position)
+		t.checkTypeCheckBranch(e)
+		whenTrue, whenFalse := t.narrow(e.Condition)
+		var ifResult, elseResult interpreter.Type
+		t.withNarrowing(whenTrue, func() {
+			t.inBlock(func() {
+				for _, stmt := range e.IfBranch {
+					t.checkStatement(stmt)
+				}
+				ifResult = t.typeOf(e.IfResult)
+			})
+		})
+		if e.ElseResult == nil {
+			return ifResult
+		}
+		t.withNarrowing(whenFalse, func() {
+			t.inBlock(func() {
+				for _, stmt := range e.ElseBranch {
+					t.checkStatement(stmt)
+				}
+				elseResult = t.typeOf(e.ElseResult)
+			})
+		})
+		return t.join([]interpreter.Type{ifResult, elseResult})
+
+	case parserlegacy.MatchExpr:
+		return t.checkMatch(e)
+
+	case parserlegacy.FuncDefExpr:
+		return t.checkFunction(e, "<anonymous>", e.Position, nil, inferredFromBody)
+
+	case parserlegacy.InvocationExpr:
+		return t.checkInvocation(e)
+	}
+	return nil
+}
+
+/*
+typeOfExpected checks an expression that must be of the expected type, returning its type like typeOf.
+A function literal takes the types of any undeclared parameters from the expected type, or otherwise infers them as described by mode.
+*/
+func (t *Typer) typeOfExpected(expr parserlegacy.Expr, expected interpreter.Type, mode inference) interpreter.Type {
+	function, isFunction := expr.(parserlegacy.FuncDefExpr)
+	if !isFunction {
+		return t.typeOf(expr)
+	}
+	expectedFunction, _ := expected.(*interpreter.FunctionType)
+	return t.checkFunction(function, "<anonymous>", function.Position, expectedFunction, mode)
+}
+
+//inferredLaterIf returns the inference for a function literal whose expected type may only be known at runtime
+func inferredLaterIf(runtimeOnly bool) inference {
+	if runtimeOnly {
+		return inferredLater
+	}
+	return inferredFromBody
+}
+
+func (t *Typer) findVariable(name string) *variable {
+	if found := t.scope.find(name); found != nil {
+		found.Type = t.resolve(found.Type)
+		found.declared = t.resolve(found.declared)
+		return found
+	}
+	hash := util.Hash(name)
+	if builtin := t.ctx.FindVariable(hash); builtin != nil {
+		if t.ctx.IsOverloaded(hash) {
+			return &variable{function: true, overloaded: true}
+		}
+		return &variable{Type: builtin.Type, Mutable: builtin.Mutable}
+	}
+	if asStruct, isStruct := t.ctx.FindType(name).(*interpreter.StructType); isStruct {
+		constructor := t.ctx.FindConstructor(asStruct.TypeName)
+		return &variable{Type: constructor.Type, function: true}
+	}
+	return nil
+}
+
+//Operators are extension functions, so only the results of the built in operators are known
+func (t *Typer) binaryType(e parserlegacy.BinaryExpr) interpreter.Type {
+	lhs := t.typeOf(e.Lhs)
+	var rhs interpreter.Type
+	switch e.Op {
+	case lexer.And:
+		//The right hand side is only evaluated if the left is true
+		whenTrue, _ := t.narrow(e.Lhs)
+		t.withNarrowing(whenTrue, func() {
+			rhs = t.typeOf(e.Rhs)
+		})
+	case lexer.Or:
+		_, whenFalse := t.narrow(e.Lhs)
+		t.withNarrowing(whenFalse, func() {
+			rhs = t.typeOf(e.Rhs)
+		})
+	default:
+		rhs = t.typeOf(e.Rhs)
+	}
+	lhs, rhs = t.resolve(lhs), t.resolve(rhs)
+	switch e.Op {
+	case lexer.And, lexer.Or:
+		return interpreter.BooleanType
+	case lexer.Equals, lexer.NotEquals:
+		return interpreter.BooleanType
+	case lexer.Add, lexer.Subtract, lexer.Multiply, lexer.Slash, lexer.Mod:
+		//An operand whose type is being inferred must have the same type as the other for the built in operators to be used
+		if variable, isUnbound := t.unbound(lhs); isUnbound && builtinOperand(e.Op, rhs) {
+			t.bind(variable, rhs)
+			lhs = rhs
+		}
+		if variable, isUnbound := t.unbound(rhs); isUnbound && builtinOperand(e.Op, lhs) {
+			t.bind(variable, lhs)
+			rhs = lhs
+		}
+		if lhs == interpreter.IntType && rhs == interpreter.IntType {
+			return interpreter.IntType
+		}
+		if e.Op == lexer.Add && lhs == interpreter.StringType && rhs == interpreter.StringType {
+			return interpreter.StringType
+		}
+	case lexer.Elvis:
+		if optional, isOptional := lhs.(*interpreter.OptionalType); isOptional {
+			return t.join([]interpreter.Type{optional.ElementType, rhs})
+		}
+	}
+	return nil
+}
+
+//builtinOperand returns true if operands of the given type have a built in implementation of the arithmetic operator
+func builtinOperand(operator lexer.TokenType, operand interpreter.Type) bool {
+	return operand == interpreter.IntType || (operator == lexer.Add && operand == interpreter.StringType)
+}
+
+func (t *Typer) checkAssignment(e parserlegacy.AssignmentExpr) {
+	if e.Context != nil {
+		receiver := t.typeOf(e.Context)
+		asStruct, isStruct := receiver.(*interpreter.StructType)
+		if !isStruct {
+			t.typeOfExpected(e.Value, nil, inferredLater)
+			return
+		}
+		property, exists := asStruct.GetProperty(e.Identifier)
+		valueType := t.typeOfExpected(e.Value, property.Type, inferredLaterIf(!exists))
+		if !exists {
+			t.report(e.Position, "No such property "+e.Identifier+" on type "+asStruct.Name())
+			return
+		}
+		if property.Modifiers&interpreter.Mut == 0 {
+			t.report(e.Position, "Cannot reassign immutable property "+e.Identifier+" of type "+asStruct.Name())
+			return
+		}
+		t.constrain(property.Type, valueType)
+		valueType = t.resolve(valueType)
+		if known(valueType) && !property.Type.Accepts(valueType, t.ctx) {
+			t.report(e.Position, "Cannot reassign property "+e.Identifier+" of type "+property.Type.Name()+" to value of type "+valueType.Name()+interpreter.DescribeMismatch(property.Type, valueType, t.ctx))
+		}
+		return
+	}
+
+	assigned := t.findVariable(e.Identifier)
+	if assigned == nil {
+		t.typeOfExpected(e.Value, nil, inferredLater)
+		return
+	}
+	assignable := assigned.assignableType()
+	valueType := t.typeOfExpected(e.Value, assignable, inferredLaterIf(assignable == nil))
+	if !assigned.Mutable {
+		t.report(e.Position, "Cannot reassign immutable variable "+e.Identifier)
+		return
+	}
+	t.constrain(assignable, valueType)
+	valueType = t.resolve(valueType)
+	if assignable != nil && known(valueType) && !assignable.Accepts(valueType, t.ctx) {
+		t.report(e.Position, "Cannot reassign variable "+e.Identifier+" of type "+assignable.Name()+" to value of type "+valueType.Name()+interpreter.DescribeMismatch(assignable, valueType, t.ctx))
+	}
+	if assigned.narrowed {
+		assigned.Type = assignable //The narrowing no longer holds for the new value
+	}
+}
+
+/*
+propertyType checks an access of a property, returning the type of the property if it is known.
+A property of an optional value can only be accessed with ?. unless the value has been narrowed to not be null.
+*/
+func (t *Typer) propertyType(e parserlegacy.ContextExpr) interpreter.Type {
+	receiver := t.typeOf(e.Context)
+	name := e.Variable.Identifier
+	if optional, isOptional := receiver.(*interpreter.OptionalType); isOptional {
+		if !e.NullSafe {
+			t.report(e.Position, "Cannot access property "+name+" of "+receiver.Name()+", which may be null. Use ?. for null safe access, or check that it is not null first")
+			return nil
+		}
+		propertyType := t.structPropertyType(optional.ElementType, name)
+		if propertyType == nil {
+			return nil
+		}
+		return interpreter.NewOptionalType(propertyType)
+	}
+	return t.structPropertyType(receiver, name)
+}
+
+//structPropertyType returns the type of the property of a struct, or of every struct in a union, or nil if it is not known
+func (t *Typer) structPropertyType(receiver interpreter.Type, name string) interpreter.Type {
+	if union, isUnion := receiver.(*interpreter.UnionType); isUnion {
+		members := union.Members()
+		propertyTypes := make([]interpreter.Type, len(members))
+		for i, member := range members {
+			propertyTypes[i] = t.structPropertyType(member, name)
+		}
+		return t.join(propertyTypes)
+	}
+	if asStruct, isStruct := receiver.(*interpreter.StructType); isStruct {
+		if property, exists := asStruct.GetProperty(name); exists {
+			return property.Type
+		}
+	}
+	return nil
+}
+
+/*
+checkInvocation checks the arguments of a call against the parameters of the function being called.
+Calls on a receiver, and calls to overloaded functions, are resolved at runtime, so only their arguments are checked.
+Generic functions are instantiated with fresh type variables for each call, which are bound by the arguments and then checked against their contracts.
+*/
+func (t *Typer) checkInvocation(e parserlegacy.InvocationExpr) interpreter.Type {
+	name := "<anonymous>"
+	var invoked interpreter.Type
+	resolvedAtRuntime := false
+	switch invoker := e.Invoker.(type) {
+	case parserlegacy.VariableExpr:
+		name = invoker.Identifier
+		found := t.findVariable(name)
+		if found == nil || found.overloaded {
+			t.checkArguments(e.Args, nil)
+			return nil
+		}
+		invoked = found.Type
+	case parserlegacy.ContextExpr:
+		//Only used to infer the parameters of function literals, as the extension actually called is chosen at runtime
+		receiver := t.typeOf(invoker.Context)
+		invoked = t.findExtension(receiver, invoker.Variable.Identifier)
+		resolvedAtRuntime = true
+	default:
+		invoked = t.resolve(t.typeOf(invoker))
+	}
+	if variable, isUnbound := t.unbound(invoked); isUnbound && !resolvedAtRuntime {
+		invoked = t.callable(variable, len(e.Args))
+	}
+
+	function, isFunction := invoked.(*interpreter.FunctionType)
+	if !isFunction || resolvedAtRuntime {
+		t.checkArguments(e.Args, function)
+		return nil
+	}
+	described := name
+	if len(function.Signature.TypeParameters) != 0 {
+		described += " (" + function.Name() + ")" //The generic type explains why the parameter was expected to be the type it is
+	}
+	typeParameters := function.Signature.TypeParameters
+	function, instantiated := t.instantiate(function)
+	arguments := t.checkArguments(e.Args, function)
+	parameters := function.Signature.Parameters
+	if len(arguments) != len(parameters) {
+		t.report(e.Position, "Illegal number of arguments for function "+name+". Expected "+strconv.Itoa(len(parameters))+", received "+strconv.Itoa(len(arguments)))
+		return nil
+	}
+	for i, argument := range arguments {
+		parameter := parameters[i]
+		t.constrain(parameter.Type, argument)
+		parameterType := t.resolve(parameter.Type)
+		argument = t.resolve(argument)
+		if known(argument) && !parameterType.Accepts(argument, t.ctx) {
+			t.report(e.Position, "Expected "+parameterType.Name()+" for parameter "+parameter.Name+" of "+described+" and got "+argument.Name()+interpreter.DescribeMismatch(parameterType, argument, t.ctx))
+		}
+	}
+	t.checkContracts(e.Position, typeParameters, instantiated, described)
+	returnType := t.resolve(function.Signature.ReturnType)
+	if !known(returnType) && len(t.freeVariables(returnType)) == 0 {
+		return nil
+	}
+	return returnType
+}
+
+//checkContracts reports the type parameters whose variables were bound to a type that does not fulfill their contract
+func (t *Typer) checkContracts(position lexer.Position, typeParameters []*interpreter.TypeParameter, bindings map[*interpreter.TypeParameter]interpreter.Type, described string) {
+	for _, parameter := range typeParameters {
+		argument := t.resolve(bindings[parameter])
+		if !known(argument) {
+			continue
+		}
+		contract := t.resolve(interpreter.Substitute(parameter.Contract, bindings))
+		if len(t.freeVariables(contract)) == 0 && !contract.Accepts(argument, t.ctx) {
+			t.report(position, "Type "+argument.Name()+" does not fulfill the contract "+contract.Name()+" of type parameter "+parameter.Name()+" of "+described)
+		}
+	}
+}
+
+/*
+checkArguments checks the arguments of a call, returning their types.
+Function literals take the types of their undeclared parameters from the parameters of function, if it is known.
+*/
+func (t *Typer) checkArguments(arguments []parserlegacy.Expr, function *interpreter.FunctionType) []interpreter.Type {
+	types := make([]interpreter.Type, len(arguments))
+	for i, argument := range arguments {
+		var expected interpreter.Type
+		if function != nil && len(function.Signature.TypeParameters) == 0 && len(function.Signature.Parameters) == len(arguments) {
+			expected = function.Signature.Parameters[i].Type
+		}
+		types[i] = t.typeOfExpected(argument, expected, inferredLaterIf(expected == nil))
+	}
+	return types
+}
+
+//declaredFunctionType is the type of a function literal as far as it is known from its annotations alone
+func (t *Typer) declaredFunctionType(fn parserlegacy.FuncDefExpr) interpreter.Type {
+	if fn.ReturnType == nil || len(fn.Generics) != 0 {
+		return nil
+	}
+	parameters := make([]interpreter.Parameter, len(fn.Arguments))
+	for i, argument := range fn.Arguments {
+		parameterType := t.resolveType(argument.Type)
+		if parameterType == nil {
+			return nil
+		}
+		parameters[i] = interpreter.Parameter{Name: argument.Name, Type: parameterType, Position: uint(i)}
+	}
+	returnType := t.resolveType(fn.ReturnType)
+	if returnType == nil {
+		return nil
+	}
+	return interpreter.NewSignatureFunctionType(interpreter.Signature{Parameters: parameters, ReturnType: returnType})
+}
+
+/*
+checkFunction checks the body of a function literal, returning the function's type if it can be known.
+If no return type is declared, it is inferred from the return statements and the final statement of the body.
+Parameters without a declared or expected type are inferred from how they are used in the body, as described by mode.
+*/
+func (t *Typer) checkFunction(fn parserlegacy.FuncDefExpr, name string, position lexer.Position, expected *interpreter.FunctionType, mode inference) interpreter.Type {
+	outerCtx := t.ctx
+	var typeParameters []*interpreter.TypeParameter
+	if len(fn.Generics) != 0 {
+		typeParameters = interpreter.NewTypeParameters(fn.Generics, t.ctx)
+		t.ctx = t.ctx.EnterTypeScope(typeParameters)
+	}
+	defer func() {
+		t.ctx = outerCtx
+	}()
+
+	current := &function{name: name}
+	if fn.ReturnType != nil {
+		current.returnType = t.resolveType(fn.ReturnType)
+	}
+	parameters := make([]interpreter.Parameter, len(fn.Arguments))
+	inferred := make([]int, 0) //The parameters whose types are being inferred from the body
+	parametersKnown := true
+	for i, argument := range fn.Arguments {
+		var parameterType interpreter.Type
+		if argument.Type != nil {
+			parameterType = t.resolveType(argument.Type)
+		} else if expected != nil && len(expected.Signature.Parameters) == len(fn.Arguments) {
+			parameterType = expected.Signature.Parameters[i].Type
+		} else if mode != inferredLater {
+			parameterType = t.fresh()
+			inferred = append(inferred, i)
+		}
+		if parameterType == nil {
+			parametersKnown = false
+		}
+		parameters[i] = interpreter.Parameter{Name: argument.Name, Type: parameterType, Position: uint(i)}
+	}
+
+	t.scope = newScope(t.scope, current)
+	var result interpreter.Type //The type of the function's result while it is being inferred
+	if mode == generalized && parametersKnown {
+		//Recursive calls can only be checked if the function's own type is known while checking its body
+		if self := t.findVariable(name); self != nil && self.function && !self.overloaded && self.Type == nil {
+			result = current.returnType
+			if result == nil {
+				result = t.fresh()
+			}
+			t.scope.define(name, &variable{Type: interpreter.NewSignatureFunctionType(interpreter.Signature{Parameters: parameters, ReturnType: result}), function: true})
+		}
+	}
+	t.scope = newScope(t.scope, current)
+	for _, parameter := range parameters {
+		t.scope.define(parameter.Name, &variable{Type: parameter.Type})
+	}
+	t.scope = newScope(t.scope, current) //Locals may shadow parameters
+
+	body, bodyPosition := t.checkBody(fn.Statement, position)
+	t.scope = t.scope.parent.parent.parent
+
+	if current.returnType != nil {
+		t.constrain(current.returnType, body)
+		body = t.resolve(body)
+		if known(body) && body != interpreter.NothingType && !current.returnType.Accepts(body, t.ctx) {
+			t.report(bodyPosition, "Function '"+name+"' did not return value of type "+current.returnType.Name()+", instead was "+body.Name())
+		}
+	}
+	returnType := current.returnType
+	if fn.ReturnType == nil {
+		returnType = t.join(append(current.returns, body))
+		if result != nil {
+			t.constrain(result, returnType)
+		}
+	}
+	if mode == inferredFromBody {
+		for _, i := range inferred {
+			if _, isUnbound := t.unbound(t.resolve(parameters[i].Type)); isUnbound {
+				t.warn(position, "The type of parameter "+parameters[i].Name+" of "+name+" could not be inferred, so it will be Any")
+			}
+		}
+	}
+	if !parametersKnown || returnType == nil {
+		return nil
+	}
+	for i := range parameters {
+		parameters[i].Type = t.resolve(parameters[i].Type)
+	}
+	functionType := interpreter.NewSignatureFunctionType(interpreter.Signature{TypeParameters: typeParameters, Parameters: parameters, ReturnType: t.resolve(returnType)})
+	if mode == generalized {
+		return t.generalize(functionType)
+	}
+	return functionType
+}
+
+//checkBody checks the statements of a function, returning the type and position of the value of the final statement
+func (t *Typer) checkBody(body parserlegacy.Stmt, position lexer.Position) (interpreter.Type, lexer.Position) {
+	block, isBlock := body.(parserlegacy.BlockStmt)
+	if !isBlock {
+		block = parserlegacy.BlockStmt{Stmts: []parserlegacy.Stmt{body}}
+	}
+	var result interpreter.Type = interpreter.UnitType
+	for _, stmt := range block.Stmts {
+		switch s := stmt.(type) {
+		case parserlegacy.ExpressionStmt:
+			result = t.typeOf(s.Expr)
+			position = s.Position
+			continue
+		case parserlegacy.ReturnStmt:
+			t.checkStatement(s)
+			result = interpreter.NothingType //The value of the body is never used
+			continue
+		case parserlegacy.VarDefStmt, parserlegacy.WhileStmt, parserlegacy.StructDefStmt, parserlegacy.TypeStmt:
+			result = interpreter.UnitType
+		default:
+			result = nil
+		}
+		t.checkStatement(stmt)
+	}
+	return result, position
+}
+
+/*
+join finds the least upper bound of types, or nil if any of them are unknown.
+Type variables being inferred must be the same type as the others, such as both branches of an if expression.
+*/
+func (t *Typer) join(types []interpreter.Type) interpreter.Type {
+	var first interpreter.Type
+	for _, joining := range types {
+		if _, isUnbound := t.unbound(t.resolve(joining)); !isUnbound && joining != nil && joining != interpreter.NothingType {
+			first = joining
+			break
+		}
+	}
+	for _, joining := range types {
+		if _, isUnbound := t.unbound(t.resolve(joining)); !isUnbound {
+			continue
+		}
+		if first == nil {
+			first = joining
+		} else {
+			t.constrain(first, joining)
+		}
+	}
+
+	var joined interpreter.Type
+	for _, joining := range types {
+		if joining == nil {
+			return nil
+		}
+		joining = t.resolve(joining)
+		if joining == interpreter.NothingType {
+			continue
+		}
+		if joined == nil {
+			joined = joining
+		} else {
+			joined = interpreter.LeastUpperBound(joined, joining, t.ctx)
+		}
+	}
+	if joined == nil {
+		return interpreter.NothingType
+	}
+	return joined
+}
+
+/*
+resolveType converts a type contract to a type.
+Contracts naming types that were never declared return nil, so that values of them are not checked.
+*/
+func (t *Typer) resolveType(contract parserlegacy.Type) (resolved interpreter.Type) {
+	if !t.declared(contract) {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			resolved = nil
+		}
+	}()
+	return interpreter.FromASTType(contract, t.ctx)
+}
+
+func (t *Typer) declared(contract parserlegacy.Type) bool {
+	switch c := contract.(type) {
+	case parserlegacy.ElementaryTypeContract:
+		return t.ctx.FindType(c.Identifier) != nil
+	case parserlegacy.GenericTypeContract:
+		if t.ctx.FindType(c.Identifier) == nil {
+			return false
+		}
+		for _, argument := range c.TypeArgs {
+			if !t.declared(argument) {
+				return false
+			}
+		}
+		return true
+	case parserlegacy.CollectionTypeContract:
+		return t.declared(c.ElemType)
+	case parserlegacy.MapTypeContract:
+		return t.declared(c.KeyType) && t.declared(c.ValueType)
+	case parserlegacy.OptionalTypeContract:
+		return t.declared(c.Type)
+	case parserlegacy.BinaryTypeContract:
+		return t.declared(c.Lhs) && t.declared(c.Rhs)
+	case parserlegacy.InvocableTypeContract:
+		for _, argument := range c.Args {
+			if !t.declared(argument) {
+				return false
+			}
+		}
+		return t.declared(c.ReturnType)
+	case parserlegacy.DefinedTypeContract:
+		for _, part := range c.DefType {
+			if !t.declared(part.DefType) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+//known returns false if a value's type at runtime may be more specific than t, such as for Any or a type parameter
+func known(t interpreter.Type) bool {
+	switch t := t.(type) {
+	case nil:
+		return false
+	case *interpreter.TypeParameter:
+		return false
+	case *interpreter.CollectionType:
+		return known(t.ElementType)
+	case *interpreter.MapType:
+		return known(t.KeyType) && known(t.ValueType)
+	case *interpreter.OptionalType:
+		return known(t.ElementType)
+	case *interpreter.ResultType:
+		return known(t.ValueType) && known(t.ErrorType)
+	case *interpreter.FunctionType:
+		for _, parameter := range t.Signature.Parameters {
+			if !known(parameter.Type) {
+				return false
+			}
+		}
+		return known(t.Signature.ReturnType)
+	case *interpreter.StructType:
+		return len(t.TypeParameters) == 0
+	}
+	return t != interpreter.AnyType
+}
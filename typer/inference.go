@@ -0,0 +1,264 @@
+package typer
+
+import (
+	"github.com/ElaraLang/elara/interpreter"
+	"strconv"
+)
+
+//inference describes how the parameters of a function literal without declared types are given their types
+type inference int
+
+const (
+	//The parameters are inferred from how they are used in the body, and are Any (which is warned about) if that is not enough
+	inferredFromBody inference = iota
+	//The parameters may still be inferred at runtime, once the function being called is known, so they are not warned about
+	inferredLater
+	//The parameters are inferred from how they are used in the body, and anything not known about them is made generic.
+	//This is only done for functions declared with let, so that each use of them can have different types.
+	generalized
+)
+
+//typeParameterNames are the names given to the type parameters of inferred generic functions, in order
+const typeParameterNames = "TUVWXYZABCDEFGHIJKLMNOPQRS"
+
+/*
+fresh creates a new type variable, which is a type that is not yet known.
+Type variables are bound to a type by how the values of them are used, such as being passed to a function or added to an Int.
+*/
+func (t *Typer) fresh() *interpreter.TypeParameter {
+	variable := interpreter.NewTypeParameter("?"+strconv.Itoa(len(t.variables)+1), interpreter.AnyType)
+	t.variables[variable] = true
+	return variable
+}
+
+//unbound returns the type variable that typ is, if it has not been bound yet
+func (t *Typer) unbound(typ interpreter.Type) (*interpreter.TypeParameter, bool) {
+	variable, isParameter := typ.(*interpreter.TypeParameter)
+	if !isParameter || !t.variables[variable] {
+		return nil, false
+	}
+	_, isBound := t.bound[variable]
+	return variable, !isBound
+}
+
+/*
+bind records that the type variable is the given type.
+A variable is never bound to Any, as that would hide that the variable could be generic,
+or to a type containing itself, which could never be written down.
+*/
+func (t *Typer) bind(variable *interpreter.TypeParameter, typ interpreter.Type) {
+	if typ == nil || typ == interpreter.AnyType || typ == variable {
+		return
+	}
+	for _, free := range t.freeVariables(typ) {
+		if free == variable {
+			return
+		}
+	}
+	bindings := map[*interpreter.TypeParameter]interpreter.Type{variable: typ}
+	for bound, boundType := range t.bound {
+		t.bound[bound] = interpreter.Substitute(boundType, bindings)
+	}
+	t.bound[variable] = typ
+}
+
+/*
+constrain records that a value of type actual is used where a value of type expected is needed,
+binding whichever of the two are unbound type variables. Function, collection, map and optional types are constrained
+part by part, so that passing (Int) => Int where (T) => T is expected binds T to Int.
+No error is reported here, as the types are checked against each other afterwards.
+*/
+func (t *Typer) constrain(expected interpreter.Type, actual interpreter.Type) {
+	expected = t.resolve(expected)
+	actual = t.resolve(actual)
+	if expected == nil || actual == nil {
+		return
+	}
+	if variable, isUnbound := t.unbound(actual); isUnbound {
+		t.bind(variable, expected)
+		return
+	}
+	if variable, isUnbound := t.unbound(expected); isUnbound {
+		t.bind(variable, actual)
+		return
+	}
+	switch e := expected.(type) {
+	case *interpreter.FunctionType:
+		a, isFunction := actual.(*interpreter.FunctionType)
+		if !isFunction || len(a.Signature.Parameters) != len(e.Signature.Parameters) {
+			return
+		}
+		for i, parameter := range e.Signature.Parameters {
+			t.constrain(a.Signature.Parameters[i].Type, parameter.Type) //Parameters are used the other way around
+		}
+		t.constrain(e.Signature.ReturnType, a.Signature.ReturnType)
+	case *interpreter.CollectionType:
+		if a, isCollection := actual.(*interpreter.CollectionType); isCollection {
+			t.constrain(e.ElementType, a.ElementType)
+		}
+	case *interpreter.MapType:
+		if a, isMap := actual.(*interpreter.MapType); isMap {
+			t.constrain(e.KeyType, a.KeyType)
+			t.constrain(e.ValueType, a.ValueType)
+		}
+	case *interpreter.OptionalType:
+		if a, isOptional := actual.(*interpreter.OptionalType); isOptional {
+			t.constrain(e.ElementType, a.ElementType)
+		} else if actual != interpreter.NullType {
+			t.constrain(e.ElementType, actual)
+		}
+	case *interpreter.StructType:
+		a, isStruct := actual.(*interpreter.StructType)
+		if !isStruct || a.TypeName != e.TypeName || len(a.TypeArguments) != len(e.TypeArguments) {
+			return
+		}
+		for i, argument := range e.TypeArguments {
+			t.constrain(argument, a.TypeArguments[i])
+		}
+	}
+}
+
+//resolve replaces every bound type variable in typ with the type it is bound to
+func (t *Typer) resolve(typ interpreter.Type) interpreter.Type {
+	if typ == nil || len(t.bound) == 0 {
+		return typ
+	}
+	for _, free := range t.freeVariables(typ) {
+		if _, isBound := t.bound[free]; isBound {
+			return interpreter.Substitute(typ, t.bound)
+		}
+	}
+	return typ
+}
+
+//freeVariables returns every type variable used in typ, in the order they first appear
+func (t *Typer) freeVariables(typ interpreter.Type) []*interpreter.TypeParameter {
+	found := make([]*interpreter.TypeParameter, 0)
+	seen := map[*interpreter.TypeParameter]bool{}
+	var visit func(interpreter.Type)
+	visit = func(typ interpreter.Type) {
+		switch typ := typ.(type) {
+		case *interpreter.TypeParameter:
+			if t.variables[typ] && !seen[typ] {
+				seen[typ] = true
+				found = append(found, typ)
+			}
+		case *interpreter.CollectionType:
+			visit(typ.ElementType)
+		case *interpreter.MapType:
+			visit(typ.KeyType)
+			visit(typ.ValueType)
+		case *interpreter.OptionalType:
+			visit(typ.ElementType)
+		case *interpreter.ResultType:
+			visit(typ.ValueType)
+			visit(typ.ErrorType)
+		case *interpreter.FunctionType:
+			for _, parameter := range typ.Signature.Parameters {
+				visit(parameter.Type)
+			}
+			visit(typ.Signature.ReturnType)
+		case *interpreter.UnionType:
+			for _, member := range typ.Members() {
+				visit(member)
+			}
+		case *interpreter.StructType:
+			for _, argument := range typ.TypeArguments {
+				visit(argument)
+			}
+		}
+	}
+	visit(typ)
+	return found
+}
+
+/*
+generalize turns the type of a function declared with let into its most general type.
+Every type variable that is still unbound, and is not used by any variable outside of the function,
+becomes a type parameter of the function, so that `let id(x) => x` has the type <T> (T) => T.
+*/
+func (t *Typer) generalize(function *interpreter.FunctionType) *interpreter.FunctionType {
+	function = t.resolve(function).(*interpreter.FunctionType)
+	inEnvironment := map[*interpreter.TypeParameter]bool{}
+	for s := t.scope; s != nil; s = s.parent {
+		for _, v := range s.variables {
+			for _, free := range t.freeVariables(t.resolve(v.Type)) {
+				inEnvironment[free] = true
+			}
+		}
+	}
+
+	used := map[string]bool{}
+	for _, parameter := range function.Signature.TypeParameters {
+		used[parameter.Name()] = true
+	}
+	typeParameters := function.Signature.TypeParameters
+	bindings := map[*interpreter.TypeParameter]interpreter.Type{}
+	for _, free := range t.freeVariables(function) {
+		if inEnvironment[free] {
+			continue
+		}
+		parameter := interpreter.NewTypeParameter(unusedTypeParameterName(used), interpreter.AnyType)
+		used[parameter.Name()] = true
+		bindings[free] = parameter
+		typeParameters = append(typeParameters, parameter)
+	}
+	if len(bindings) == 0 {
+		return function
+	}
+	signature := interpreter.Substitute(function, bindings).(*interpreter.FunctionType).Signature
+	signature.TypeParameters = typeParameters
+	return interpreter.NewSignatureFunctionType(signature)
+}
+
+//unusedTypeParameterName returns the first of T, U, V and so on that is not used, adding a number once every letter is
+func unusedTypeParameterName(used map[string]bool) string {
+	for suffix := 0; ; suffix++ {
+		for _, letter := range typeParameterNames {
+			name := string(letter)
+			if suffix != 0 {
+				name += strconv.Itoa(suffix)
+			}
+			if !used[name] {
+				return name
+			}
+		}
+	}
+}
+
+//instantiate replaces the type parameters of a generic function with fresh type variables, so that they can be bound by a single call.
+//It also returns the variable each type parameter was replaced with, for checking their contracts once they are bound.
+func (t *Typer) instantiate(function *interpreter.FunctionType) (*interpreter.FunctionType, map[*interpreter.TypeParameter]interpreter.Type) {
+	if len(function.Signature.TypeParameters) == 0 {
+		return function, nil
+	}
+	bindings := make(map[*interpreter.TypeParameter]interpreter.Type, len(function.Signature.TypeParameters))
+	for _, parameter := range function.Signature.TypeParameters {
+		bindings[parameter] = t.fresh()
+	}
+	return interpreter.Substitute(function, bindings).(*interpreter.FunctionType), bindings
+}
+
+//callable binds an unbound type variable that is called to a function type, with fresh type variables for its parameters and result
+func (t *Typer) callable(variable *interpreter.TypeParameter, arity int) *interpreter.FunctionType {
+	parameters := make([]interpreter.Parameter, arity)
+	for i := range parameters {
+		parameters[i] = interpreter.Parameter{Name: strconv.Itoa(i + 1), Type: t.fresh(), Position: uint(i)}
+	}
+	function := interpreter.NewSignatureFunctionType(interpreter.Signature{Parameters: parameters, ReturnType: t.fresh()})
+	t.bind(variable, function)
+	return function
+}
+
+//TypeOfVariable returns the inferred type of a variable declared at the top level of the program, such as for showing it in an editor.
+//It returns nil if the variable does not exist or its type can only be known at runtime.
+func (t *Typer) TypeOfVariable(name string) interpreter.Type {
+	if t.scope == nil {
+		return nil
+	}
+	found := t.scope.find(name)
+	if found == nil {
+		return nil
+	}
+	return t.resolve(found.Type)
+}
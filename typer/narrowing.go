@@ -0,0 +1,159 @@
+package typer
+
+import (
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+)
+
+//narrowing maps the names of variables to the more specific types they are known to have in a branch
+type narrowing map[string]interpreter.Type
+
+/*
+narrow finds the types that variables are known to have when condition is true, and when it is false.
+Variables are narrowed by `is` and `!is` checks, comparisons to null, and chains of these joined by && or ||.
+*/
+func (t *Typer) narrow(condition parserlegacy.Expr) (whenTrue narrowing, whenFalse narrowing) {
+	whenTrue = narrowing{}
+	whenFalse = narrowing{}
+	switch c := condition.(type) {
+	case parserlegacy.GroupExpr:
+		return t.narrow(c.Group)
+
+	case parserlegacy.UnaryExpr:
+		if c.Op == lexer.Not {
+			whenFalse, whenTrue = t.narrow(c.Rhs)
+		}
+
+	case parserlegacy.TypeCheckExpr:
+		checked, isVariable := c.Expr.(parserlegacy.VariableExpr)
+		if !isVariable {
+			break
+		}
+		found := t.findVariable(checked.Identifier)
+		if found == nil {
+			break
+		}
+		checkedType := t.resolveType(c.Type)
+		whenTrue[checked.Identifier] = checkedType
+		if known(found.Type) && checkedType != nil {
+			whenFalse[checked.Identifier] = without(found.Type, checkedType, t.ctx)
+		}
+
+	case parserlegacy.BinaryExpr:
+		switch c.Op {
+		case lexer.And:
+			lhsTrue, _ := t.narrow(c.Lhs)
+			var rhsTrue narrowing
+			t.withNarrowing(lhsTrue, func() {
+				rhsTrue, _ = t.narrow(c.Rhs)
+			})
+			whenTrue = lhsTrue.merge(rhsTrue)
+		case lexer.Or:
+			_, lhsFalse := t.narrow(c.Lhs)
+			var rhsFalse narrowing
+			t.withNarrowing(lhsFalse, func() {
+				_, rhsFalse = t.narrow(c.Rhs)
+			})
+			whenFalse = lhsFalse.merge(rhsFalse)
+		case lexer.Equals, lexer.NotEquals:
+			name, nonNull := t.nullComparison(c)
+			if name == "" {
+				break
+			}
+			whenTrue[name] = interpreter.NullType
+			whenFalse[name] = nonNull
+			if c.Op == lexer.NotEquals {
+				whenTrue, whenFalse = whenFalse, whenTrue
+			}
+		}
+	}
+	return whenTrue, whenFalse
+}
+
+//nullComparison returns the name of the optional variable compared to null by e, and its type when it is not null
+func (t *Typer) nullComparison(e parserlegacy.BinaryExpr) (string, interpreter.Type) {
+	compared := e.Lhs
+	if _, isNull := compared.(parserlegacy.NullLiteralExpr); isNull {
+		compared = e.Rhs
+	} else if _, isNull := e.Rhs.(parserlegacy.NullLiteralExpr); !isNull {
+		return "", nil
+	}
+	variable, isVariable := compared.(parserlegacy.VariableExpr)
+	if !isVariable {
+		return "", nil
+	}
+	found := t.findVariable(variable.Identifier)
+	if found == nil {
+		return "", nil
+	}
+	optional, isOptional := found.Type.(*interpreter.OptionalType)
+	if !isOptional {
+		return "", nil
+	}
+	return variable.Identifier, optional.ElementType
+}
+
+//merge returns the narrowing of n, with the narrowing of other taking priority
+func (n narrowing) merge(other narrowing) narrowing {
+	merged := narrowing{}
+	for name, narrowed := range n {
+		merged[name] = narrowed
+	}
+	for name, narrowed := range other {
+		merged[name] = narrowed
+	}
+	return merged
+}
+
+//withNarrowing runs check with the variables of n having their narrowed types
+func (t *Typer) withNarrowing(n narrowing, check func()) {
+	t.scope = newScope(t.scope, t.scope.function)
+	for name, narrowed := range n {
+		original := t.findVariable(name)
+		t.scope.define(name, &variable{
+			Type:       narrowed,
+			Mutable:    original.Mutable,
+			function:   original.function,
+			overloaded: original.overloaded,
+			narrowed:   true,
+			declared:   original.assignableType(),
+		})
+	}
+	check()
+	t.scope = t.scope.parent
+}
+
+//without returns the type of the values of from that are not of type removed
+func without(from interpreter.Type, removed interpreter.Type, ctx *interpreter.Context) interpreter.Type {
+	if removed.Accepts(from, ctx) {
+		return interpreter.NothingType
+	}
+	switch f := from.(type) {
+	case *interpreter.OptionalType:
+		if removed.Accepts(interpreter.NullType, ctx) {
+			return f.ElementType
+		}
+		if removed.Accepts(f.ElementType, ctx) {
+			return interpreter.NullType
+		}
+	case *interpreter.UnionType:
+		var remaining interpreter.Type
+		for _, member := range f.Members() {
+			member = without(member, removed, ctx)
+			if member == interpreter.NothingType {
+				continue
+			}
+			if remaining == nil {
+				remaining = member
+			} else {
+				remaining = interpreter.NewUnionType(remaining, member)
+			}
+		}
+		if remaining == nil {
+			return interpreter.NothingType
+		}
+		return remaining
+	}
+	return from
+}
@@ -0,0 +1,64 @@
+package typer
+
+import "github.com/ElaraLang/elara/interpreter"
+
+type variable struct {
+	Type    interpreter.Type //nil if the type can only be known at runtime
+	Mutable bool
+
+	function   bool
+	overloaded bool
+
+	narrowed bool             //The variable has a more specific type in this scope, such as after an `is` check
+	declared interpreter.Type //The type before narrowing, which is what the variable can be assigned
+}
+
+//assignableType is the type that values assigned to v must have
+func (v *variable) assignableType() interpreter.Type {
+	if v.narrowed {
+		return v.declared
+	}
+	return v.Type
+}
+
+//extension is a function declared in an extend block, which is called on a receiver of the extended type
+type extension struct {
+	receiver interpreter.Type
+	name     string
+	variable *variable
+}
+
+//function collects what is known about the function whose body is being checked
+type function struct {
+	name       string
+	returnType interpreter.Type   //The declared return type, or nil if it is inferred
+	returns    []interpreter.Type //The types of every return statement, used to infer the return type
+}
+
+type scope struct {
+	parent    *scope
+	variables map[string]*variable
+	function  *function //nil outside of any function body
+	block     bool      //The scope of a block, whose variables may shadow those outside of it with a warning
+}
+
+func newScope(parent *scope, function *function) *scope {
+	return &scope{
+		parent:    parent,
+		variables: map[string]*variable{},
+		function:  function,
+	}
+}
+
+func (s *scope) define(name string, v *variable) {
+	s.variables[name] = v
+}
+
+func (s *scope) find(name string) *variable {
+	for current := s; current != nil; current = current.parent {
+		if v, exists := current.variables[name]; exists {
+			return v
+		}
+	}
+	return nil
+}
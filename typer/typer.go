@@ -1,45 +1,277 @@
 package typer
 
-import "github.com/ElaraLang/elara/parserlegacy"
+import (
+	"fmt"
+	"github.com/ElaraLang/elara/interpreter"
+	"github.com/ElaraLang/elara/lexer"
+	"github.com/ElaraLang/elara/parserlegacy"
+)
 
+//Typer statically checks a parsed program, so that type errors are reported before any of it is executed.
+//Types that can only be known at runtime (such as Any, or unannotated parameters) are never reported as errors.
 type Typer struct {
-	Input []parserlegacy.Stmt
+	Input    []parserlegacy.Stmt
+	Errors   []TypeError
+	Warnings []TypeWarning
+
+	ctx        *interpreter.Context //Holds the built in values and every type declared by the program
+	scope      *scope
+	extensions []extension
+
+	variables map[*interpreter.TypeParameter]bool             //Every type variable created while inferring types
+	bound     map[*interpreter.TypeParameter]interpreter.Type //The types that type variables are known to be
+}
+
+func NewTyper(input []parserlegacy.Stmt) *Typer {
+	return &Typer{Input: input}
+}
+
+//HandleTyping checks every statement in order, returning all of the type errors found
+func (t *Typer) HandleTyping() []TypeError {
+	t.ctx = interpreter.NewContext(true)
+	t.scope = newScope(nil, nil)
+	t.Errors = make([]TypeError, 0)
+	t.Warnings = make([]TypeWarning, 0)
+	t.extensions = make([]extension, 0)
+	t.variables = map[*interpreter.TypeParameter]bool{}
+	t.bound = map[*interpreter.TypeParameter]interpreter.Type{}
+	for _, stmt := range t.Input {
+		t.checkStatement(stmt)
+	}
+	return t.Errors
 }
 
-func (t *Typer) HandleTyping() {
-	// Pass 1 - Scanning for types
-	types := t.scanForUserDefinedTypes()
-	// Pass 2 - Scanning for function return types
-	functionReturns := t.scanForFunctionReturns()
+func (t *Typer) report(position lexer.Position, message string) {
+	t.Errors = append(t.Errors, TypeError{Position: position, Message: message})
 }
 
-func (t *Typer) scanForUserDefinedTypes() []parserlegacy.Type {
-	types := make([]parserlegacy.Type, 0)
-	var cur parserlegacy.Stmt
-	for index := range t.Input {
-		cur = t.Input[index]
-		switch cur.(type) {
+func (t *Typer) warn(position lexer.Position, message string) {
+	t.Warnings = append(t.Warnings, TypeWarning{Position: position, Message: message})
+}
+
+func (t *Typer) checkStatement(stmt parserlegacy.Stmt) {
+	switch s := stmt.(type) {
+	case parserlegacy.ExpressionStmt:
+		t.typeOf(s.Expr)
+	case parserlegacy.VarDefStmt:
+		t.checkVarDef(s, nil)
+	case parserlegacy.BlockStmt:
+		t.inBlock(func() {
+			for _, line := range s.Stmts {
+				t.checkStatement(line)
+			}
+		})
+	case parserlegacy.IfElseStmt:
+		t.checkCondition(s.Condition, s.Position)
+		whenTrue, whenFalse := t.narrow(s.Condition)
+		t.withNarrowing(whenTrue, func() {
+			t.checkStatement(s.MainBranch)
+		})
+		if s.ElseBranch != nil {
+			t.withNarrowing(whenFalse, func() {
+				t.checkStatement(s.ElseBranch)
+			})
+		}
+	case parserlegacy.WhileStmt:
+		t.checkCondition(s.Condition, s.Position)
+		t.checkStatement(s.Body)
+	case parserlegacy.StructDefStmt:
+		t.declare(s, s.Position)
+		t.checkDefaults(s)
+	case parserlegacy.TypeStmt:
+		t.declare(s, s.Position)
+	case parserlegacy.GenerifiedStmt:
+		switch generified := s.Statement.(type) {
+		case parserlegacy.VarDefStmt:
+			t.checkVarDef(generified, s.Contracts)
 		case parserlegacy.StructDefStmt:
-			structDef := cur.(parserlegacy.StructDefStmt)
-			appendType(&types, &structDef)
+			generified.Generics = append(s.Contracts, generified.Generics...)
+			t.declare(generified, generified.Position)
+		}
+	case parserlegacy.ExtendStmt:
+		t.checkExtend(s)
+	case parserlegacy.ReturnStmt:
+		t.checkReturn(s)
+	case parserlegacy.ThrowStmt:
+		t.typeOf(s.Error)
+	case parserlegacy.TryStmt:
+		t.checkStatement(s.Body)
+		for _, catch := range s.Catches {
+			caught := interpreter.Type(interpreter.ErrorType)
+			if catch.Type != nil {
+				caught = t.resolveType(catch.Type)
+			}
+			t.scope = newScope(t.scope, t.scope.function)
+			t.scope.define(catch.Identifier, &variable{Type: caught})
+			t.checkStatement(catch.Body)
+			t.scope = t.scope.parent
 		}
+		if s.Finally != nil {
+			t.checkStatement(s.Finally)
+		}
+	}
+}
+
+//inBlock checks the statements of a block in a new scope, so that the variables they declare are discarded afterwards
+func (t *Typer) inBlock(check func()) {
+	t.scope = newScope(t.scope, t.scope.function)
+	t.scope.block = true
+	check()
+	t.scope = t.scope.parent
+}
+
+//declare registers a struct or type alias without running any code, reporting any problem with the declaration itself
+func (t *Typer) declare(stmt parserlegacy.Stmt, position lexer.Position) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.report(position, fmt.Sprint(r))
+		}
+	}()
+	interpreter.DeclareType(stmt, t.ctx)
+}
+
+//checkDefaults checks the default values of a struct's properties against their types, as they are not evaluated by declare
+func (t *Typer) checkDefaults(s parserlegacy.StructDefStmt) {
+	declared, isStruct := t.ctx.FindType(s.Identifier).(*interpreter.StructType)
+	if !isStruct || len(declared.TypeParameters) != 0 {
+		return
+	}
+	for _, field := range s.StructFields {
+		if field.Default == nil {
+			continue
+		}
+		property, _ := declared.GetProperty(field.Identifier)
+		valueType := t.resolve(t.typeOfExpected(field.Default, property.Type, inferredFromBody))
+		if known(valueType) && !property.Type.Accepts(valueType, t.ctx) {
+			t.report(s.Position, "Cannot use value of type "+valueType.Name()+" in place of "+property.Type.Name()+" for property "+field.Identifier)
+		}
+	}
+}
+
+func (t *Typer) checkCondition(condition parserlegacy.Expr, position lexer.Position) {
+	conditionType := t.typeOf(condition)
+	t.constrain(interpreter.BooleanType, conditionType)
+	conditionType = t.resolve(conditionType)
+	if known(conditionType) && !interpreter.BooleanType.Accepts(conditionType, t.ctx) {
+		t.report(position, "Condition must be a Boolean, but was "+conditionType.Name())
+	}
+}
+
+func (t *Typer) checkVarDef(s parserlegacy.VarDefStmt, generics []parserlegacy.GenericContract) {
+	existing := t.scope.variables[s.Identifier]
+	function, isFunction := s.Value.(parserlegacy.FuncDefExpr)
+	if existing != nil && !(existing.function && isFunction) {
+		t.report(s.Position, "Variable named "+s.Identifier+" already exists")
+	}
+	if existing == nil && t.scope.block && t.scope.parent.find(s.Identifier) != nil {
+		t.warn(s.Position, "Variable "+s.Identifier+" shadows a variable outside of the block")
+	}
+
+	var declared interpreter.Type
+	if s.Type != nil {
+		declared = t.resolveType(s.Type)
+	}
+	var valueType interpreter.Type
+	if isFunction {
+		function.Generics = append(generics, function.Generics...)
+		//The name is defined before the body is checked, so that recursive calls can be checked too
+		t.defineVar(s, existing, t.declaredFunctionType(function))
+		expected, _ := declared.(*interpreter.FunctionType)
+		valueType = t.checkFunction(function, s.Identifier, function.Position, expected, generalized)
+	} else {
+		valueType = t.typeOfExpected(s.Value, declared, inferredFromBody)
+	}
+	t.constrain(declared, valueType)
+	valueType = t.resolve(valueType)
+
+	if declared != nil && known(valueType) && !declared.Accepts(valueType, t.ctx) {
+		t.report(s.Position, "Cannot use value of type "+valueType.Name()+" in place of "+declared.Name()+" for variable "+s.Identifier+interpreter.DescribeMismatch(declared, valueType, t.ctx))
+	}
+	if s.Type != nil {
+		valueType = declared
 	}
-	return types
+	t.defineVar(s, existing, valueType)
 }
 
-func (t *Typer) scanForFunctionReturns() []parserlegacy.Type {
+//defineVar defines the variable declared by s. A function defined more than once is overloaded,
+//so calls to it cannot be checked without knowing which overload will be chosen.
+func (t *Typer) defineVar(s parserlegacy.VarDefStmt, existing *variable, variableType interpreter.Type) {
+	_, isFunction := s.Value.(parserlegacy.FuncDefExpr)
+	if existing != nil && existing.function && isFunction {
+		t.scope.define(s.Identifier, &variable{function: true, overloaded: true})
+		return
+	}
+	t.scope.define(s.Identifier, &variable{Type: variableType, Mutable: s.Mutable, function: isFunction})
+}
 
+func (t *Typer) checkReturn(s parserlegacy.ReturnStmt) {
+	returnedType := interpreter.Type(interpreter.UnitType)
+	if s.Returning != nil {
+		returnedType = t.typeOf(s.Returning)
+	}
+	function := t.scope.function
+	if function == nil {
+		return
+	}
+	function.returns = append(function.returns, returnedType)
+	t.constrain(function.returnType, returnedType)
+	returnedType = t.resolve(returnedType)
+	if function.returnType != nil && known(returnedType) && !function.returnType.Accepts(returnedType, t.ctx) {
+		t.report(s.Position, "Function '"+function.name+"' did not return value of type "+function.returnType.Name()+", instead was "+returnedType.Name())
+	}
 }
 
-func appendType(types *[]parserlegacy.Type, p *parserlegacy.StructDefStmt) {
-	fields := make([]parserlegacy.DefinedType, 0)
-	for fieldIndex := range p.StructFields {
-		curField := p.StructFields[fieldIndex]
-		field := parserlegacy.DefinedType{
-			Identifier: curField.Identifier,
-			DefType:    *curField.FieldType,
+/*
+checkExtend checks the functions declared in an extend block.
+Inside their bodies, the receiver and its properties can be used without qualification.
+*/
+func (t *Typer) checkExtend(s parserlegacy.ExtendStmt) {
+	structs := make([]parserlegacy.Stmt, 0)
+	for _, stmt := range s.Body.Stmts {
+		if _, isStruct := stmt.(parserlegacy.StructDefStmt); isStruct {
+			structs = append(structs, stmt)
 		}
-		fields = append(fields, field)
 	}
-	*types = append(*types, parserlegacy.DefinedTypeContract{DefType: fields, Name: p.Identifier})
+	t.declare(parserlegacy.ExtendStmt{Identifier: s.Identifier, Body: parserlegacy.BlockStmt{Stmts: structs}, Alias: s.Alias}, s.Position)
+	for _, stmt := range structs {
+		t.checkDefaults(stmt.(parserlegacy.StructDefStmt))
+	}
+
+	extending := t.ctx.FindType(s.Identifier)
+	t.scope = newScope(t.scope, nil)
+	t.scope.define(s.Alias, &variable{Type: extending})
+	if asStruct, isStruct := extending.(*interpreter.StructType); isStruct {
+		for _, property := range asStruct.Properties {
+			t.scope.define(property.Name, &variable{Type: property.Type, Mutable: property.Modifiers&interpreter.Mut != 0})
+		}
+	}
+	t.scope = newScope(t.scope, nil) //Extensions may share names with properties
+	for _, stmt := range s.Body.Stmts {
+		if varDef, isVarDef := stmt.(parserlegacy.VarDefStmt); isVarDef {
+			t.checkVarDef(varDef, nil)
+			t.extensions = append(t.extensions, extension{receiver: extending, name: varDef.Identifier, variable: t.scope.variables[varDef.Identifier]})
+		}
+	}
+	t.scope = t.scope.parent.parent
+}
+
+//findExtension returns the type of the extension with the given name on receiver, or nil if there is not exactly one that could be called
+func (t *Typer) findExtension(receiver interpreter.Type, name string) interpreter.Type {
+	if !known(receiver) {
+		return nil
+	}
+	var found *variable
+	for _, ext := range t.extensions {
+		if ext.name != name || ext.receiver == nil || !ext.receiver.Accepts(receiver, t.ctx) {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = ext.variable
+	}
+	if found == nil || found.overloaded {
+		return nil
+	}
+	return found.Type
 }